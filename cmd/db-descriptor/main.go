@@ -6,7 +6,10 @@ USAGE:
 
 COMMANDS:
 
-	help, h  Shows a list of commands or help for one command
+	check-queries   checks that the tables and columns used by SQL files exist and have compatible types
+	describe-query  describes the columns of the results of SQL queries without executing them
+	profile         describes the database and profiles the contents of the columns of its tables
+	help, h         Shows a list of commands or help for one command
 
 GLOBAL OPTIONS:
 
@@ -18,15 +21,47 @@ GLOBAL OPTIONS:
 	--schemas value, -s value [ --schemas value, -s value ]  comma separated list of schemas to describe (default: "public")
 	--dbtype value, --dt value                               specify the database type (default: "postgres")
 	--output value, -o value                                 JSON output file name the description of the database (default: "output.json")
+	--lineage-output value, --lo value                       DOT output file name for the lineage graph of the views (not generated if empty)
+	--permissions-output value, --po value                   CSV output file name for the matrix of privileges of each role on each object (not generated if empty)
+	--sensitivity-output value, --so value                   CSV output file name for the report of the columns classified as sensitive, by schema (not generated if empty)
+	--collapse-partitions, --cp                              list partitions inside their partitioned table instead of as entities of the schema (default: false)
+	--routine-source, --rs                                   include the source code of functions and procedures in the description (default: false)
+	--settings value [ --settings value ]                    comma separated list of server settings to include in the description (default: "TimeZone", "search_path", "default_transaction_isolation", "max_connections")
+	--storage-stats, --ss                                    include sizes, row estimates and vacuum and analyze times of the entities (default: false)
+	--exact-counts, --ec                                     count the rows of the entities with storage (default: false)
+	--exact-counts-budget value, --ecb value                 maximum time to spend counting rows in total (default: 1m0s)
+	--column-stats, --cs                                     include the statistics of the planner about each column (default: false)
+	--max-common-values value, --mcv value                   maximum number of most common values kept in the statistics of a column (default: 10)
+	--no-sample-schemas value [ --no-sample-schemas value ]  comma separated list of schemas whose column statistics must not include values
+	--category-threshold value, --ct value                   list the values of the columns with at most this number of distinct values (none if 0) (default: 0)
+	--category-include value [ --category-include value ]    comma separated list of patterns (column, table.column or schema.table.column) of the columns whose values can be listed (all if empty)
+	--category-exclude value [ --category-exclude value ]    comma separated list of patterns of the columns whose values are never listed (default: "id", "*_id", "*name", "*description", "*comment*", "*email*", "*note*")
+	--reference-tables value [ --reference-tables value ]    comma separated list of patterns (table or schema.table) of reference tables whose rows are included
+	--detect-reference-tables, --drt                         include the rows of small tables with a code and a label referenced by several foreign keys (default: false)
+	--reference-max-rows value, --rmr value                  maximum number of rows read from a reference table (no limit if 0) (default: 200)
+	--json-schema-samples value, --jss value                 number of rows sampled to infer the JSON Schema of json and jsonb columns (not inferred if 0) (default: 0)
+	--example-rows value, --er value                         number of example rows included for each entity (none if 0) (default: 0)
+	--mask value, -m value [ --mask value, -m value ]        comma separated list of rules pattern=strategy (hash, redact or fake) masking every value of the columns matching the pattern, or with a classification or category matching name if the pattern is classification:name
+	--classify                                               classify the columns that may contain sensitive data (PII or PHI) by their names, comments and values (default: false)
+	--classify-config value, --clc value                     JSON file with the rules to classify columns, replacing the default ones
+	--classify-samples value, --cls value                    number of rows whose values are checked to classify the columns (values not checked if 0) (default: 0)
+	--infer-relations, --ir                                  infer the relations that are not declared from the names and data types of the columns (default: false)
+	--infer-relations-samples value, --irs value             number of distinct values of a column checked in the column it is inferred to reference (not checked if 0) (default: 0)
+	--candidate-key-samples value, --cks value               number of rows read to look for candidate keys of the tables without primary key (not looked for if 0) (default: 0)
+	--queries value, -q value [ --queries value, -q value ]  comma separated list of SQL files, or directories of .sql files, whose results are described in the description
 	--help, -h                                               show help
 */
 package main
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/PDCMFinder/db-descriptor/pkg/connector"
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
 	"github.com/PDCMFinder/db-descriptor/pkg/report"
 	"github.com/PDCMFinder/db-descriptor/pkg/service"
 	"github.com/urfave/cli/v2"
@@ -41,6 +76,83 @@ func main() {
 	var schemas cli.StringSlice
 	var dbtype string
 	var output string
+	var lineageOutput string
+	var permissionsOutput string
+	var sensitivityOutput string
+	var collapsePartitions bool
+	var routineSource bool
+	var settings cli.StringSlice
+	var storageStats bool
+	var exactCounts bool
+	var exactCountsBudget time.Duration
+	var columnStats bool
+	var maxCommonValues int
+	var noSampleSchemas cli.StringSlice
+	var sampleRows int
+	var samplePercent float64
+	var topValues int
+	var concurrency int
+	var categoryThreshold int
+	var categoryInclude cli.StringSlice
+	var categoryExclude cli.StringSlice
+	var referenceTables cli.StringSlice
+	var detectReferenceTables bool
+	var referenceMaxRows int
+	var jsonSchemaSamples int
+	var exampleRows int
+	var maskingRules cli.StringSlice
+	var classify bool
+	var classifyConfig string
+	var classifySamples int
+	var inferRelations bool
+	var inferRelationsSamples int
+	var candidateKeySamples int
+	var queryFiles cli.StringSlice
+	var querySql string
+	var descriptionFile string
+
+	// The global options, shared by all the commands
+	getInput := func() connector.Input {
+		return connector.Input{
+			Host:                  host,
+			Port:                  port,
+			User:                  user,
+			Password:              password,
+			Name:                  name,
+			Schemas:               schemas.Value(),
+			Db:                    dbtype,
+			CollapsePartitions:    collapsePartitions,
+			IncludeRoutineSource:  routineSource,
+			Settings:              settings.Value(),
+			IncludeStorageStats:   storageStats,
+			ExactCounts:           exactCounts,
+			ExactCountsBudget:     exactCountsBudget,
+			IncludeColumnStats:    columnStats,
+			MaxMostCommonValues:   maxCommonValues,
+			NoValueSampleSchemas:  noSampleSchemas.Value(),
+			CategoryThreshold:     categoryThreshold,
+			CategoryInclude:       categoryInclude.Value(),
+			CategoryExclude:       categoryExclude.Value(),
+			ReferenceTables:       referenceTables.Value(),
+			DetectReferenceTables: detectReferenceTables,
+			ReferenceMaxRows:      referenceMaxRows,
+			JsonSchemaSamples:     jsonSchemaSamples,
+			SampleRows:            exampleRows,
+			MaskingRules:          maskingRules.Value(),
+			// The sensitivity report needs the classifications
+			Classify:                 classify || sensitivityOutput != "",
+			ClassificationConfigFile: classifyConfig,
+			ClassificationSamples:    classifySamples,
+			InferRelations:           inferRelations,
+			InferRelationsSamples:    inferRelationsSamples,
+			CandidateKeySamples:      candidateKeySamples,
+			QueryFiles:               queryFiles.Value(),
+		}
+	}
+	getOutputFiles := func() OutputFiles {
+		return OutputFiles{
+			Description: output, Lineage: lineageOutput, Permissions: permissionsOutput, Sensitivity: sensitivityOutput}
+	}
 
 	app := &cli.App{
 		Name:  "db-descriptor",
@@ -102,18 +214,276 @@ func main() {
 				Usage:       "JSON output file name the description of the database",
 				Destination: &output,
 			},
+			&cli.StringFlag{
+				Name:        "lineage-output",
+				Aliases:     []string{"lo"},
+				Usage:       "DOT output file name for the lineage graph of the views (not generated if empty)",
+				Destination: &lineageOutput,
+			},
+			&cli.StringFlag{
+				Name:        "permissions-output",
+				Aliases:     []string{"po"},
+				Usage:       "CSV output file name for the matrix of privileges of each role on each object (not generated if empty)",
+				Destination: &permissionsOutput,
+			},
+			&cli.StringFlag{
+				Name:        "sensitivity-output",
+				Aliases:     []string{"so"},
+				Usage:       "CSV output file name for the report of the columns classified as sensitive, by schema (not generated if empty)",
+				Destination: &sensitivityOutput,
+			},
+			&cli.BoolFlag{
+				Name:        "collapse-partitions",
+				Aliases:     []string{"cp"},
+				Usage:       "list partitions inside their partitioned table instead of as entities of the schema",
+				Destination: &collapsePartitions,
+			},
+			&cli.BoolFlag{
+				Name:        "routine-source",
+				Aliases:     []string{"rs"},
+				Usage:       "include the source code of functions and procedures in the description",
+				Destination: &routineSource,
+			},
+			&cli.StringSliceFlag{
+				Name:        "settings",
+				Value:       cli.NewStringSlice("TimeZone", "search_path", "default_transaction_isolation", "max_connections"),
+				Usage:       "comma separated list of server settings to include in the description",
+				Destination: &settings,
+			},
+			&cli.BoolFlag{
+				Name:        "storage-stats",
+				Aliases:     []string{"ss"},
+				Usage:       "include sizes, row estimates and vacuum and analyze times of the entities",
+				Destination: &storageStats,
+			},
+			&cli.BoolFlag{
+				Name:        "exact-counts",
+				Aliases:     []string{"ec"},
+				Usage:       "count the rows of the entities with storage",
+				Destination: &exactCounts,
+			},
+			&cli.DurationFlag{
+				Name:        "exact-counts-budget",
+				Aliases:     []string{"ecb"},
+				Value:       time.Minute,
+				Usage:       "maximum time to spend counting rows in total",
+				Destination: &exactCountsBudget,
+			},
+			&cli.BoolFlag{
+				Name:        "column-stats",
+				Aliases:     []string{"cs"},
+				Usage:       "include the statistics of the planner about each column",
+				Destination: &columnStats,
+			},
+			&cli.IntFlag{
+				Name:        "max-common-values",
+				Aliases:     []string{"mcv"},
+				Value:       10,
+				Usage:       "maximum number of most common values kept in the statistics of a column",
+				Destination: &maxCommonValues,
+			},
+			&cli.StringSliceFlag{
+				Name:        "no-sample-schemas",
+				Usage:       "comma separated list of schemas whose column statistics must not include values",
+				Destination: &noSampleSchemas,
+			},
+			&cli.IntFlag{
+				Name:        "category-threshold",
+				Aliases:     []string{"ct"},
+				Usage:       "list the values of the columns with at most this number of distinct values (none if 0)",
+				Destination: &categoryThreshold,
+			},
+			&cli.StringSliceFlag{
+				Name:        "category-include",
+				Usage:       "comma separated list of patterns (column, table.column or schema.table.column) of the columns whose values can be listed (all if empty)",
+				Destination: &categoryInclude,
+			},
+			&cli.StringSliceFlag{
+				Name:        "category-exclude",
+				Value:       cli.NewStringSlice("id", "*_id", "*name", "*description", "*comment*", "*email*", "*note*"),
+				Usage:       "comma separated list of patterns of the columns whose values are never listed",
+				Destination: &categoryExclude,
+			},
+			&cli.StringSliceFlag{
+				Name:        "reference-tables",
+				Usage:       "comma separated list of patterns (table or schema.table) of reference tables whose rows are included",
+				Destination: &referenceTables,
+			},
+			&cli.BoolFlag{
+				Name:        "detect-reference-tables",
+				Aliases:     []string{"drt"},
+				Usage:       "include the rows of small tables with a code and a label referenced by several foreign keys",
+				Destination: &detectReferenceTables,
+			},
+			&cli.IntFlag{
+				Name:        "reference-max-rows",
+				Aliases:     []string{"rmr"},
+				Value:       200,
+				Usage:       "maximum number of rows read from a reference table (no limit if 0)",
+				Destination: &referenceMaxRows,
+			},
+			&cli.IntFlag{
+				Name:        "json-schema-samples",
+				Aliases:     []string{"jss"},
+				Usage:       "number of rows sampled to infer the JSON Schema of json and jsonb columns (not inferred if 0)",
+				Destination: &jsonSchemaSamples,
+			},
+			&cli.IntFlag{
+				Name:        "example-rows",
+				Aliases:     []string{"er"},
+				Usage:       "number of example rows included for each entity (none if 0)",
+				Destination: &exampleRows,
+			},
+			&cli.StringSliceFlag{
+				Name:        "mask",
+				Aliases:     []string{"m"},
+				Usage:       "comma separated list of rules pattern=strategy (hash, redact or fake) masking every value of the columns matching the pattern, or with a classification or category matching name if the pattern is classification:name",
+				Destination: &maskingRules,
+			},
+			&cli.BoolFlag{
+				Name:        "classify",
+				Usage:       "classify the columns that may contain sensitive data (PII or PHI) by their names, comments and values",
+				Destination: &classify,
+			},
+			&cli.StringFlag{
+				Name:        "classify-config",
+				Aliases:     []string{"clc"},
+				Usage:       "JSON file with the rules to classify columns, replacing the default ones",
+				Destination: &classifyConfig,
+			},
+			&cli.IntFlag{
+				Name:        "classify-samples",
+				Aliases:     []string{"cls"},
+				Usage:       "number of rows whose values are checked to classify the columns (values not checked if 0)",
+				Destination: &classifySamples,
+			},
+			&cli.BoolFlag{
+				Name:        "infer-relations",
+				Aliases:     []string{"ir"},
+				Usage:       "infer the relations that are not declared from the names and data types of the columns",
+				Destination: &inferRelations,
+			},
+			&cli.IntFlag{
+				Name:        "infer-relations-samples",
+				Aliases:     []string{"irs"},
+				Usage:       "number of distinct values of a column checked in the column it is inferred to reference (not checked if 0)",
+				Destination: &inferRelationsSamples,
+			},
+			&cli.IntFlag{
+				Name:        "candidate-key-samples",
+				Aliases:     []string{"cks"},
+				Usage:       "number of rows read to look for candidate keys of the tables without primary key (not looked for if 0)",
+				Destination: &candidateKeySamples,
+			},
+			&cli.StringSliceFlag{
+				Name:        "queries",
+				Aliases:     []string{"q"},
+				Usage:       "comma separated list of SQL files, or directories of .sql files, whose results are described in the description",
+				Destination: &queryFiles,
+			},
 		},
 		Action: func(cCtx *cli.Context) error {
-			input := connector.Input{
-				Host:     host,
-				Port:     port,
-				User:     user,
-				Password: password,
-				Name:     name,
-				Schemas:  schemas.Value(),
-				Db:       dbtype,
-			}
-			return RunDBDescriptor(input, cCtx.String("output"))
+			return RunDBDescriptor(getInput(), getOutputFiles())
+		},
+		Commands: []*cli.Command{
+			{
+				Name:      "check-queries",
+				Usage:     "checks that the tables and columns used by SQL files exist and have compatible types",
+				ArgsUsage: "[SQL file or directory]...",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:        "description",
+						Aliases:     []string{"d"},
+						Usage:       "JSON description of the database to check against, instead of connecting to the database",
+						Destination: &descriptionFile,
+					},
+				},
+				Action: func(cCtx *cli.Context) error {
+					queryFiles := cCtx.Args().Slice()
+					if len(queryFiles) == 0 {
+						return errors.New("no queries to check: pass SQL files or directories")
+					}
+					var issues []model.QueryIssue
+					if descriptionFile != "" {
+						issues = service.CheckQueriesAgainstDescription(descriptionFile, queryFiles)
+					} else {
+						input := getInput()
+						input.QueryFiles = queryFiles
+						issues = service.CheckQueries(input)
+					}
+					report.WriteQueryIssues(issues, os.Stdout)
+					if len(issues) > 0 {
+						return cli.Exit(fmt.Sprintf("%d problems found in the queries", len(issues)), 1)
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "describe-query",
+				Usage:     "describes the columns of the results of SQL queries without executing them",
+				ArgsUsage: "[SQL file or directory]...",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:        "sql",
+						Usage:       "text of a query to describe, in addition to the files",
+						Destination: &querySql,
+					},
+				},
+				Action: func(cCtx *cli.Context) error {
+					input := getInput()
+					input.QueryFiles = append(input.QueryFiles, cCtx.Args().Slice()...)
+					if querySql != "" {
+						input.QueryTexts = []string{querySql}
+					}
+					if len(input.QueryFiles) == 0 && len(input.QueryTexts) == 0 {
+						return errors.New("no queries to describe: pass SQL files or directories, or --sql")
+					}
+					report.WriteQueriesAsJson(service.DescribeQueries(input), output)
+					return nil
+				},
+			},
+			{
+				Name:  "profile",
+				Usage: "describes the database and profiles the contents of the columns of its tables",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:        "sample-rows",
+						Aliases:     []string{"sr"},
+						Value:       10000,
+						Usage:       "maximum number of rows read from each table (no limit if 0)",
+						Destination: &sampleRows,
+					},
+					&cli.Float64Flag{
+						Name:        "sample-percent",
+						Aliases:     []string{"sp"},
+						Usage:       "percentage of the pages of each table sampled with TABLESAMPLE (the whole table if 0)",
+						Destination: &samplePercent,
+					},
+					&cli.IntFlag{
+						Name:        "top-values",
+						Aliases:     []string{"tv"},
+						Value:       10,
+						Usage:       "number of most frequent values kept for each column",
+						Destination: &topValues,
+					},
+					&cli.IntFlag{
+						Name:        "concurrency",
+						Aliases:     []string{"c"},
+						Value:       4,
+						Usage:       "number of tables profiled at the same time",
+						Destination: &concurrency,
+					},
+				},
+				Action: func(cCtx *cli.Context) error {
+					input := getInput()
+					input.Profile = true
+					input.ProfileSampleRows = sampleRows
+					input.ProfileSamplePercent = samplePercent
+					input.ProfileTopValues = topValues
+					input.ProfileConcurrency = concurrency
+					return RunDBDescriptor(input, getOutputFiles())
+				},
+			},
 		},
 	}
 
@@ -122,8 +492,25 @@ func main() {
 	}
 }
 
-func RunDBDescriptor(input connector.Input, outputFileName string) error {
+// Names of the files the reports are written to. Optional reports are not written when their file name is empty.
+type OutputFiles struct {
+	Description string
+	Lineage     string
+	Permissions string
+	Sensitivity string
+}
+
+func RunDBDescriptor(input connector.Input, outputFiles OutputFiles) error {
 	databaseDescription := service.GetDbDescription(input)
-	report.WriteDbDescriptionAsJson(databaseDescription, outputFileName)
+	report.WriteDbDescriptionAsJson(databaseDescription, outputFiles.Description)
+	if outputFiles.Lineage != "" {
+		report.WriteLineageAsDot(databaseDescription, outputFiles.Lineage)
+	}
+	if outputFiles.Permissions != "" {
+		report.WritePermissionMatrixAsCsv(databaseDescription, outputFiles.Permissions)
+	}
+	if outputFiles.Sensitivity != "" {
+		report.WriteSensitivityReportAsCsv(databaseDescription, outputFiles.Sensitivity)
+	}
 	return nil
 }
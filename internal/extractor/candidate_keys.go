@@ -0,0 +1,225 @@
+package extractor
+
+import (
+	"database/sql"
+	"log"
+	"strings"
+
+	"github.com/PDCMFinder/db-descriptor/pkg/connector"
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
+)
+
+// Maximum number of columns of a candidate key
+const maxCandidateKeyColumns = 3
+
+// Maximum number of sets of columns tested for each table, so wide tables do not take too long
+const maxCandidateKeyTests = 5000
+
+/*
+Populates the tables and partitioned tables in `dataMap` that have no primary key with their candidate keys: the sets
+of up to `maxCandidateKeyColumns` columns without nulls whose values are unique in the first `input.CandidateKeySamples`
+rows. Only minimal sets are kept, so a set is not tested if it contains a candidate key already found. Columns whose
+values are documents, binary data or arrays are not considered.
+
+The result of each table is also logged, to point out the tables that lack a primary key.
+*/
+func populateCandidateKeys(
+	dataMap map[string]map[string]model.Entity, dBConnector connector.DBConnector, input connector.Input, db *sql.DB) {
+	for _, e := range getEntitiesWithStorage(dataMap, false) {
+		entity := dataMap[e.SchemaName][e.EntityName]
+		if entity.EntityType == model.MaterializedView || hasPrimaryKey(entity) {
+			continue
+		}
+
+		columnNames := make([]string, 0, len(entity.Columns))
+		for _, c := range entity.Columns {
+			dataType := strings.ToLower(c.DataType)
+			if !strings.HasSuffix(dataType, "[]") && !containsName(nonCategoricalDataTypes, dataType) {
+				columnNames = append(columnNames, c.Name)
+			}
+		}
+		if len(columnNames) == 0 {
+			continue
+		}
+
+		// One more row than the budget is read to know if the sample is the whole table
+		schemaName, entityName := getEntityQueryNames(entity)
+		queryStatement := dBConnector.GetSampleRowsQueryStatement(
+			schemaName, entityName, getColumnQueryNames(entity, columnNames), 0, input.CandidateKeySamples+1)
+		rows, err := getSampleRowsList(queryStatement, len(columnNames), db)
+		if err != nil {
+			log.Printf("Could not look for candidate keys of %s.%s. Error: %s", entity.SchemaName, entity.Name,
+				err.Error())
+			continue
+		}
+		isVerified := len(rows) <= input.CandidateKeySamples
+		if !isVerified {
+			rows = rows[:input.CandidateKeySamples]
+		}
+		if len(rows) == 0 {
+			log.Printf("%s.%s has no primary key and no rows to look for candidate keys", entity.SchemaName, entity.Name)
+			continue
+		}
+
+		entity.CandidateKeys = findCandidateKeys(columnNames, rows, isVerified)
+		logCandidateKeys(entity, len(rows))
+		dataMap[e.SchemaName][e.EntityName] = entity
+	}
+}
+
+func hasPrimaryKey(entity model.Entity) bool {
+	for _, uniqueKey := range entity.UniqueKeys {
+		if uniqueKey.IsPrimaryKey {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the minimal sets of columns, smallest first, without nulls and with unique values in `rows`. The values of
+// each row are in the same order as `columnNames`
+func findCandidateKeys(columnNames []string, rows [][]*string, isVerified bool) []model.CandidateKey {
+	// Columns with nulls cannot be part of a key
+	nonNullIndexes := make([]int, 0, len(columnNames))
+	for i := range columnNames {
+		hasNulls := false
+		for _, row := range rows {
+			if row[i] == nil {
+				hasNulls = true
+				break
+			}
+		}
+		if !hasNulls {
+			nonNullIndexes = append(nonNullIndexes, i)
+		}
+	}
+
+	candidateKeys := make([]model.CandidateKey, 0)
+	// Sets of column indexes of the candidate keys found
+	found := make([][]int, 0)
+	tests := 0
+	for size := 1; size <= maxCandidateKeyColumns && size <= len(nonNullIndexes); size++ {
+		combinations := newCombinationIterator(nonNullIndexes, size)
+		for indexes, ok := combinations.next(); ok; indexes, ok = combinations.next() {
+			if containsAnyKey(indexes, found) {
+				continue
+			}
+			if tests == maxCandidateKeyTests {
+				log.Printf("Stopped looking for candidate keys after testing %d sets of columns", tests)
+				return candidateKeys
+			}
+			tests++
+			if !isUniqueInRows(indexes, rows) {
+				continue
+			}
+
+			found = append(found, indexes)
+			candidateKey := model.CandidateKey{SampledRows: int64(len(rows)), IsVerified: isVerified}
+			for _, i := range indexes {
+				candidateKey.ColumnNames = append(candidateKey.ColumnNames, columnNames[i])
+			}
+			candidateKeys = append(candidateKeys, candidateKey)
+		}
+	}
+	return candidateKeys
+}
+
+// Iterates over the combinations of `size` elements of a list, keeping their order. Combinations are generated one at
+// a time, as there are too many to build them all for wide tables
+type combinationIterator struct {
+	values []int
+	size   int
+	// Positions in `values` of the elements of the last combination returned, nil before the first one
+	positions []int
+}
+
+func newCombinationIterator(values []int, size int) *combinationIterator {
+	return &combinationIterator{values: values, size: size}
+}
+
+// Returns the next combination, or false when there are no more
+func (it *combinationIterator) next() ([]int, bool) {
+	if it.size > len(it.values) {
+		return nil, false
+	}
+	if it.positions == nil {
+		it.positions = make([]int, it.size)
+		for i := range it.positions {
+			it.positions[i] = i
+		}
+	} else {
+		// Advance the rightmost position that can move, and put the ones after it right behind it
+		i := it.size - 1
+		for i >= 0 && it.positions[i] == len(it.values)-it.size+i {
+			i--
+		}
+		if i < 0 {
+			return nil, false
+		}
+		it.positions[i]++
+		for j := i + 1; j < it.size; j++ {
+			it.positions[j] = it.positions[j-1] + 1
+		}
+	}
+
+	combination := make([]int, it.size)
+	for i, position := range it.positions {
+		combination[i] = it.values[position]
+	}
+	return combination, true
+}
+
+// Returns true if every set of indexes of any of `keys` is in `indexes`
+func containsAnyKey(indexes []int, keys [][]int) bool {
+	for _, key := range keys {
+		containsKey := true
+		for _, k := range key {
+			isIncluded := false
+			for _, i := range indexes {
+				isIncluded = isIncluded || i == k
+			}
+			containsKey = containsKey && isIncluded
+		}
+		if containsKey {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if no 2 rows have the same values in the columns at `indexes`
+func isUniqueInRows(indexes []int, rows [][]*string) bool {
+	seen := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		values := make([]string, 0, len(indexes))
+		for _, i := range indexes {
+			values = append(values, *row[i])
+		}
+		// The separator cannot appear in text values, so different rows give different keys
+		key := strings.Join(values, "\x00")
+		if seen[key] {
+			return false
+		}
+		seen[key] = true
+	}
+	return true
+}
+
+// Logs the candidate keys of an entity without primary key
+func logCandidateKeys(entity model.Entity, sampledRows int) {
+	if len(entity.CandidateKeys) == 0 {
+		log.Printf("%s.%s has no primary key and no candidate key in %d rows", entity.SchemaName, entity.Name,
+			sampledRows)
+		return
+	}
+	keys := make([]string, 0, len(entity.CandidateKeys))
+	for _, k := range entity.CandidateKeys {
+		keys = append(keys, "("+strings.Join(k.ColumnNames, ", ")+")")
+	}
+	scope := "in all its"
+	if !entity.CandidateKeys[0].IsVerified {
+		scope = "in a sample of"
+	}
+	log.Printf("%s.%s has no primary key. Candidate keys %s %d rows: %s", entity.SchemaName, entity.Name, scope,
+		sampledRows, strings.Join(keys, " "))
+}
@@ -0,0 +1,121 @@
+package extractor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
+)
+
+// Returns rows of values, with nil for the empty strings
+func makeRows(values ...[]string) [][]*string {
+	rows := make([][]*string, 0, len(values))
+	for _, v := range values {
+		row := make([]*string, len(v))
+		for i := range v {
+			if v[i] != "" {
+				value := v[i]
+				row[i] = &value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestFindCandidateKeys(t *testing.T) {
+	tests := []struct {
+		name        string
+		columnNames []string
+		rows        [][]*string
+		isVerified  bool
+		expected    []model.CandidateKey
+	}{
+		{
+			name:        "single column key",
+			columnNames: []string{"code", "label"},
+			rows:        makeRows([]string{"a", "x"}, []string{"b", "x"}),
+			isVerified:  true,
+			expected: []model.CandidateKey{
+				{ColumnNames: []string{"code"}, SampledRows: 2, IsVerified: true},
+			},
+		},
+		{
+			name:        "composite key without its columns alone",
+			columnNames: []string{"patient", "visit", "note"},
+			rows: makeRows(
+				[]string{"1", "1", "x"},
+				[]string{"1", "2", "x"},
+				[]string{"2", "1", "x"}),
+			expected: []model.CandidateKey{
+				{ColumnNames: []string{"patient", "visit"}, SampledRows: 3},
+			},
+		},
+		{
+			name:        "supersets of keys are not keys",
+			columnNames: []string{"id", "code", "label"},
+			rows:        makeRows([]string{"1", "a", "x"}, []string{"2", "a", "y"}),
+			expected: []model.CandidateKey{
+				{ColumnNames: []string{"id"}, SampledRows: 2},
+				{ColumnNames: []string{"label"}, SampledRows: 2},
+			},
+		},
+		{
+			name:        "columns with nulls are not keys",
+			columnNames: []string{"email", "name"},
+			rows:        makeRows([]string{"a@b.c", "x"}, []string{"", "x"}),
+			expected:    []model.CandidateKey{},
+		},
+		{
+			name:        "duplicated rows",
+			columnNames: []string{"a", "b"},
+			rows:        makeRows([]string{"1", "2"}, []string{"1", "2"}),
+			expected:    []model.CandidateKey{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keys := findCandidateKeys(test.columnNames, test.rows, test.isVerified)
+			if !reflect.DeepEqual(keys, test.expected) {
+				t.Errorf("findCandidateKeys() = %+v, expected %+v", keys, test.expected)
+			}
+		})
+	}
+}
+
+func TestFindCandidateKeysOfWideTable(t *testing.T) {
+	// No set of up to 3 columns is unique, so the number of tests is capped
+	columnNames := make([]string, 300)
+	row := make([]string, 300)
+	for i := range columnNames {
+		columnNames[i] = "c"
+		row[i] = "v"
+	}
+	keys := findCandidateKeys(columnNames, makeRows(row, row), false)
+	if len(keys) != 0 {
+		t.Errorf("expected no candidate keys, got %+v", keys)
+	}
+}
+
+func TestCombinationIterator(t *testing.T) {
+	tests := []struct {
+		values   []int
+		size     int
+		expected [][]int
+	}{
+		{[]int{1, 2, 3}, 1, [][]int{{1}, {2}, {3}}},
+		{[]int{1, 2, 3}, 2, [][]int{{1, 2}, {1, 3}, {2, 3}}},
+		{[]int{1, 2, 3, 4}, 3, [][]int{{1, 2, 3}, {1, 2, 4}, {1, 3, 4}, {2, 3, 4}}},
+		{[]int{1, 2}, 3, [][]int{}},
+	}
+	for _, test := range tests {
+		combinations := make([][]int, 0)
+		iterator := newCombinationIterator(test.values, test.size)
+		for combination, ok := iterator.next(); ok; combination, ok = iterator.next() {
+			combinations = append(combinations, combination)
+		}
+		if !reflect.DeepEqual(combinations, test.expected) {
+			t.Errorf("combinations of %d of %v = %v, expected %v", test.size, test.values, combinations, test.expected)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package extractor
+
+import (
+	"database/sql"
+	"log"
+	"strings"
+
+	"github.com/PDCMFinder/db-descriptor/pkg/connector"
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
+)
+
+// Data types whose values are documents or binary data, which are never listed as categories
+var nonCategoricalDataTypes = []string{"json", "jsonb", "bytea", "xml", "tsvector", "tsquery"}
+
+/*
+Populates the columns of the tables, partitioned tables and materialized views in `dataMap` that have at most
+`input.CategoryThreshold` distinct values with the list of their values and frequencies.
+
+Only the columns selected by the include and exclude patterns in `input` are considered, and never the columns that
+identify rows (single column unique keys) or whose values are documents or arrays. Columns whose statistics or profile
+already show more distinct values than the threshold are not queried.
+*/
+func populateCategoricalValues(
+	dataMap map[string]map[string]model.Entity, dBConnector connector.DBConnector, input connector.Input, db *sql.DB) {
+	for _, e := range getEntitiesWithStorage(dataMap, false) {
+		entity := dataMap[e.SchemaName][e.EntityName]
+		for i := range entity.Columns {
+			column := &entity.Columns[i]
+			if !isCategoricalCandidate(entity, *column, input) {
+				continue
+			}
+
+			// One more value than the threshold is requested to know if the column has too many
+			schemaName, entityName := getEntityQueryNames(entity)
+			queryStatement := dBConnector.GetValueFrequenciesQueryStatement(
+				schemaName, entityName, getColumnQueryNames(entity, []string{column.Name})[0], input.CategoryThreshold+1)
+			values, err := getValueFrequenciesList(queryStatement, db)
+			if err != nil {
+				log.Printf("Could not list the values of %s.%s.%s. Error: %s", entity.SchemaName, entity.Name,
+					column.Name, err.Error())
+				continue
+			}
+			if len(values) <= input.CategoryThreshold {
+				column.CategoricalValues = values
+			}
+		}
+		dataMap[e.SchemaName][e.EntityName] = entity
+	}
+}
+
+// Returns true if the values of a column can be listed according to the patterns in `input`, its type and what is
+// already known about its number of distinct values
+func isCategoricalCandidate(entity model.Entity, column model.Column, input connector.Input) bool {
+	if len(input.CategoryInclude) > 0 &&
+		!matchesColumnPattern(input.CategoryInclude, entity.SchemaName, entity.Name, column.Name) {
+		return false
+	}
+	if matchesColumnPattern(input.CategoryExclude, entity.SchemaName, entity.Name, column.Name) {
+		return false
+	}
+
+	dataType := strings.ToLower(column.DataType)
+	if strings.HasSuffix(dataType, "[]") || containsName(nonCategoricalDataTypes, dataType) {
+		return false
+	}
+
+	// Each value of a unique column identifies a row
+	if column.IsPrimaryKey {
+		return false
+	}
+	for _, uniqueKey := range entity.UniqueKeys {
+		if len(uniqueKey.ColumnNames) == 1 && uniqueKey.ColumnNames[0] == column.Name {
+			return false
+		}
+	}
+
+	threshold := int64(input.CategoryThreshold)
+	if column.Stats != nil && column.Stats.DistinctCount > threshold {
+		return false
+	}
+	if column.Profile != nil && column.Profile.DistinctCount > threshold {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,443 @@
+package extractor
+
+import (
+	"strings"
+
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
+)
+
+/*
+The domain of a column derived from a check constraint.
+
+Only simple expressions are understood: equality against a list of literals (`col IN (...)`, `col = ANY (ARRAY[...])`
+or several equalities joined by OR) and comparisons against literals joined by AND (`col >= 0 AND col <= 10`,
+`col BETWEEN 0 AND 10`). Anything else produces no domain.
+*/
+type checkDomain struct {
+	columnName    string
+	allowedValues []string
+	valueRange    *model.ValueRange
+}
+
+// Parses the definition of a check constraint and returns the domain it imposes on a single column, if any.
+func deriveCheckDomain(expression string) (checkDomain, bool) {
+	tokens, ok := tokenizeSQL(expression)
+	if !ok {
+		return checkDomain{}, false
+	}
+	parser := checkParser{tokens: stripCasts(tokens)}
+	parser.acceptKeyword("CHECK")
+	node, ok := parser.parseDisjunction()
+	if !ok {
+		return checkDomain{}, false
+	}
+
+	if column, values, ok := collectAllowedValues(node); ok {
+		return checkDomain{columnName: column, allowedValues: values}, true
+	}
+	if column, valueRange, ok := collectValueRange(node); ok {
+		return checkDomain{columnName: column, valueRange: valueRange}, true
+	}
+	return checkDomain{}, false
+}
+
+/*
+A node of a parsed check expression.
+
+A node is either a conjunction/disjunction of other nodes (`operator` is "and" or "or") or a single predicate.
+*/
+type checkNode struct {
+	operator  string
+	children  []checkNode
+	predicate checkPredicate
+}
+
+/*
+A comparison between a column and literal values.
+
+`operator` is one of "=", "<>", "<", "<=", ">", ">=", "in", "is null" or "is not null". An empty column means that the
+predicate compares something other than a plain column.
+*/
+type checkPredicate struct {
+	column   string
+	operator string
+	values   []string
+}
+
+type checkOperand struct {
+	column    string
+	values    []string
+	isLiteral bool
+}
+
+type checkParser struct {
+	tokens []sqlToken
+	pos    int
+}
+
+func (p *checkParser) parseDisjunction() (checkNode, bool) {
+	return p.parseJunction("OR", "or", p.parseConjunction)
+}
+
+func (p *checkParser) parseConjunction() (checkNode, bool) {
+	return p.parseJunction("AND", "and", p.parseUnary)
+}
+
+func (p *checkParser) parseJunction(keyword string, operator string, parseChild func() (checkNode, bool)) (checkNode, bool) {
+	first, ok := parseChild()
+	if !ok {
+		return checkNode{}, false
+	}
+	nodes := []checkNode{first}
+	for p.acceptKeyword(keyword) {
+		next, ok := parseChild()
+		if !ok {
+			return checkNode{}, false
+		}
+		nodes = append(nodes, next)
+	}
+	if len(nodes) == 1 {
+		return first, true
+	}
+	return checkNode{operator: operator, children: nodes}, true
+}
+
+func (p *checkParser) parseUnary() (checkNode, bool) {
+	// A parenthesis can open a nested expression or just wrap the left operand of a predicate
+	if p.peekSymbol("(") {
+		start := p.pos
+		p.pos++
+		if node, ok := p.parseDisjunction(); ok && p.acceptSymbol(")") {
+			return node, true
+		}
+		p.pos = start
+	}
+	return p.parsePredicate()
+}
+
+func (p *checkParser) parsePredicate() (checkNode, bool) {
+	left, ok := p.parseOperand()
+	if !ok {
+		return checkNode{}, false
+	}
+
+	if p.acceptKeyword("IS") {
+		operator := "is null"
+		if p.acceptKeyword("NOT") {
+			operator = "is not null"
+		}
+		if !p.acceptKeyword("NULL") {
+			return checkNode{}, false
+		}
+		return checkNode{predicate: checkPredicate{column: left.column, operator: operator}}, true
+	}
+
+	if p.acceptKeyword("IN") {
+		values, ok := p.parseLiteralList("(", ")")
+		if !ok {
+			return checkNode{}, false
+		}
+		return checkNode{predicate: checkPredicate{column: left.column, operator: "in", values: values}}, true
+	}
+
+	if p.acceptKeyword("BETWEEN") {
+		low, ok := p.parseOperand()
+		if !ok || !p.acceptKeyword("AND") {
+			return checkNode{}, false
+		}
+		high, ok := p.parseOperand()
+		if !ok || !low.isLiteral || !high.isLiteral {
+			return checkNode{}, false
+		}
+		return checkNode{operator: "and", children: []checkNode{
+			{predicate: checkPredicate{column: left.column, operator: ">=", values: low.values}},
+			{predicate: checkPredicate{column: left.column, operator: "<=", values: high.values}},
+		}}, true
+	}
+
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != symbolToken {
+		return checkNode{}, false
+	}
+	operator := p.tokens[p.pos].text
+	if operator == "!=" {
+		operator = "<>"
+	}
+	switch operator {
+	case "=", "<>", "<", "<=", ">", ">=":
+		p.pos++
+	default:
+		return checkNode{}, false
+	}
+
+	if operator == "=" && p.acceptKeyword("ANY") {
+		if !p.acceptSymbol("(") {
+			return checkNode{}, false
+		}
+		array, ok := p.parseOperand()
+		if !ok || !array.isLiteral || !p.acceptSymbol(")") {
+			return checkNode{}, false
+		}
+		return checkNode{predicate: checkPredicate{column: left.column, operator: "in", values: array.values}}, true
+	}
+
+	right, ok := p.parseOperand()
+	if !ok {
+		return checkNode{}, false
+	}
+	// Normalise literals to the right side of the comparison
+	if left.isLiteral && !right.isLiteral {
+		left, right = right, left
+		operator = flipComparison(operator)
+	}
+	if !right.isLiteral || len(right.values) != 1 {
+		return checkNode{predicate: checkPredicate{operator: operator}}, true
+	}
+	return checkNode{predicate: checkPredicate{column: left.column, operator: operator, values: right.values}}, true
+}
+
+func (p *checkParser) parseOperand() (checkOperand, bool) {
+	if p.pos >= len(p.tokens) {
+		return checkOperand{}, false
+	}
+	t := p.tokens[p.pos]
+	switch {
+	case t.kind == symbolToken && t.text == "(":
+		p.pos++
+		operand, ok := p.parseOperand()
+		if !ok || !p.acceptSymbol(")") {
+			return checkOperand{}, false
+		}
+		return operand, true
+	case t.kind == stringToken || t.kind == numberToken:
+		p.pos++
+		return checkOperand{values: []string{t.text}, isLiteral: true}, true
+	case t.kind == symbolToken && t.text == "-":
+		if p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].kind == numberToken {
+			p.pos += 2
+			return checkOperand{values: []string{"-" + p.tokens[p.pos-1].text}, isLiteral: true}, true
+		}
+		return checkOperand{}, false
+	case t.kind == identifierToken && !t.quoted:
+		word := strings.ToUpper(t.text)
+		if word == "ARRAY" && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].text == "[" {
+			p.pos++
+			values, ok := p.parseLiteralList("[", "]")
+			if !ok {
+				return checkOperand{}, false
+			}
+			return checkOperand{values: values, isLiteral: true}, true
+		}
+		if word == "TRUE" || word == "FALSE" {
+			p.pos++
+			return checkOperand{values: []string{strings.ToLower(word)}, isLiteral: true}, true
+		}
+		if isReservedCheckWord(word) {
+			return checkOperand{}, false
+		}
+		// A function call is an expression we do not try to interpret
+		if p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].text == "(" {
+			p.pos++
+			if !p.skipParenthesis() {
+				return checkOperand{}, false
+			}
+			return checkOperand{}, true
+		}
+		return p.parseColumnReference(), true
+	case t.kind == identifierToken:
+		return p.parseColumnReference(), true
+	}
+	return checkOperand{}, false
+}
+
+// Parses a possibly qualified column reference and keeps only the column name
+func (p *checkParser) parseColumnReference() checkOperand {
+	name := p.tokens[p.pos].text
+	p.pos++
+	for p.pos+1 < len(p.tokens) && p.tokens[p.pos].text == "." && p.tokens[p.pos+1].kind == identifierToken {
+		name = p.tokens[p.pos+1].text
+		p.pos += 2
+	}
+	return checkOperand{column: name}
+}
+
+func (p *checkParser) parseLiteralList(open string, close string) ([]string, bool) {
+	if !p.acceptSymbol(open) {
+		return nil, false
+	}
+	values := make([]string, 0)
+	for {
+		operand, ok := p.parseOperand()
+		if !ok || !operand.isLiteral {
+			return nil, false
+		}
+		values = append(values, operand.values...)
+		if p.acceptSymbol(close) {
+			return values, true
+		}
+		if !p.acceptSymbol(",") {
+			return nil, false
+		}
+	}
+}
+
+// Skips a balanced group of parenthesis starting at the current position
+func (p *checkParser) skipParenthesis() bool {
+	depth := 0
+	for ; p.pos < len(p.tokens); p.pos++ {
+		if p.tokens[p.pos].kind != symbolToken {
+			continue
+		}
+		if p.tokens[p.pos].text == "(" {
+			depth++
+		} else if p.tokens[p.pos].text == ")" {
+			depth--
+			if depth == 0 {
+				p.pos++
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *checkParser) peekSymbol(symbol string) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].kind == symbolToken && p.tokens[p.pos].text == symbol
+}
+
+func (p *checkParser) acceptSymbol(symbol string) bool {
+	if p.peekSymbol(symbol) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *checkParser) acceptKeyword(keyword string) bool {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == identifierToken && !p.tokens[p.pos].quoted &&
+		strings.EqualFold(p.tokens[p.pos].text, keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func isReservedCheckWord(word string) bool {
+	switch word {
+	case "AND", "OR", "NOT", "IN", "IS", "NULL", "BETWEEN", "ANY", "CHECK":
+		return true
+	}
+	return false
+}
+
+func flipComparison(operator string) string {
+	switch operator {
+	case "<":
+		return ">"
+	case "<=":
+		return ">="
+	case ">":
+		return "<"
+	case ">=":
+		return "<="
+	}
+	return operator
+}
+
+// Returns the list of values a column is restricted to by equality predicates
+func collectAllowedValues(node checkNode) (string, []string, bool) {
+	switch node.operator {
+	case "":
+		predicate := node.predicate
+		if predicate.column == "" || (predicate.operator != "=" && predicate.operator != "in") {
+			return "", nil, false
+		}
+		return predicate.column, predicate.values, true
+	case "or":
+		var column string
+		values := make([]string, 0)
+		seen := make(map[string]bool)
+		for _, child := range node.children {
+			childColumn, childValues, ok := collectAllowedValues(child)
+			if !ok || (column != "" && !strings.EqualFold(column, childColumn)) {
+				return "", nil, false
+			}
+			column = childColumn
+			for _, v := range childValues {
+				if !seen[v] {
+					seen[v] = true
+					values = append(values, v)
+				}
+			}
+		}
+		return column, values, true
+	case "and":
+		// `col IS NOT NULL AND col IN (...)` still restricts the values of col
+		var column string
+		var values []string
+		for _, child := range node.children {
+			if child.operator == "" && child.predicate.operator == "is not null" {
+				continue
+			}
+			if values != nil {
+				return "", nil, false
+			}
+			childColumn, childValues, ok := collectAllowedValues(child)
+			if !ok {
+				return "", nil, false
+			}
+			column, values = childColumn, childValues
+		}
+		return column, values, values != nil
+	}
+	return "", nil, false
+}
+
+// Returns the range of values a column is restricted to by comparison predicates
+func collectValueRange(node checkNode) (string, *model.ValueRange, bool) {
+	predicates := make([]checkPredicate, 0)
+	if !collectRangePredicates(node, &predicates) || len(predicates) == 0 {
+		return "", nil, false
+	}
+
+	var column string
+	valueRange := model.ValueRange{}
+	for _, predicate := range predicates {
+		if predicate.operator == "is not null" {
+			continue
+		}
+		if predicate.column == "" || (column != "" && !strings.EqualFold(column, predicate.column)) {
+			return "", nil, false
+		}
+		column = predicate.column
+		switch predicate.operator {
+		case ">", ">=":
+			valueRange.Min = predicate.values[0]
+			valueRange.MinInclusive = predicate.operator == ">="
+		case "<", "<=":
+			valueRange.Max = predicate.values[0]
+			valueRange.MaxInclusive = predicate.operator == "<="
+		default:
+			return "", nil, false
+		}
+	}
+	if column == "" {
+		return "", nil, false
+	}
+	return column, &valueRange, true
+}
+
+func collectRangePredicates(node checkNode, predicates *[]checkPredicate) bool {
+	switch node.operator {
+	case "":
+		*predicates = append(*predicates, node.predicate)
+		return true
+	case "and":
+		for _, child := range node.children {
+			if !collectRangePredicates(child, predicates) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package extractor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
+)
+
+func TestDeriveCheckDomain(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		ok         bool
+		expected   checkDomain
+	}{
+		{
+			name:       "list of values",
+			expression: "CHECK ((status = ANY (ARRAY['active'::text, 'retired'::text])))",
+			ok:         true,
+			expected:   checkDomain{columnName: "status", allowedValues: []string{"active", "retired"}},
+		},
+		{
+			name:       "in list",
+			expression: "CHECK (sex IN ('male', 'female'))",
+			ok:         true,
+			expected:   checkDomain{columnName: "sex", allowedValues: []string{"male", "female"}},
+		},
+		{
+			name:       "equalities joined by or",
+			expression: "CHECK (((grade = 1) OR (grade = 2)))",
+			ok:         true,
+			expected:   checkDomain{columnName: "grade", allowedValues: []string{"1", "2"}},
+		},
+		{
+			name:       "closed range",
+			expression: "CHECK (((age >= 0) AND (age < 130)))",
+			ok:         true,
+			expected: checkDomain{columnName: "age", valueRange: &model.ValueRange{
+				Min: "0", MinInclusive: true, Max: "130"}},
+		},
+		{
+			name:       "literal on the left",
+			expression: "CHECK ((0 < weight))",
+			ok:         true,
+			expected:   checkDomain{columnName: "weight", valueRange: &model.ValueRange{Min: "0"}},
+		},
+		{
+			name:       "between",
+			expression: "CHECK (score BETWEEN 1 AND 5)",
+			ok:         true,
+			expected: checkDomain{columnName: "score", valueRange: &model.ValueRange{
+				Min: "1", MinInclusive: true, Max: "5", MaxInclusive: true}},
+		},
+		{
+			name:       "several columns",
+			expression: "CHECK ((start_date <= end_date))",
+		},
+		{
+			name:       "function call",
+			expression: "CHECK ((length(code) = 3))",
+		},
+		{
+			name:       "unterminated string",
+			expression: "CHECK (status IN ('a",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			domain, ok := deriveCheckDomain(test.expression)
+			if ok != test.ok {
+				t.Fatalf("deriveCheckDomain(%q) returned %v, expected %v", test.expression, ok, test.ok)
+			}
+			if !reflect.DeepEqual(domain, test.expected) {
+				t.Errorf("deriveCheckDomain(%q) = %+v, expected %+v", test.expression, domain, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package extractor
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/PDCMFinder/db-descriptor/pkg/connector"
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
+)
+
+// Categories of sensitive data
+const (
+	// Personally identifiable information
+	piiCategory = "PII"
+	// Protected health information
+	phiCategory = "PHI"
+)
+
+// Minimum fraction of the sampled values a detector must match, when its rule does not set one
+const defaultMinMatchFraction = 0.8
+
+/*
+A rule to classify columns. What Pattern is depends on the list the rule is in:
+  - nameRules: a column pattern (see matchesColumnPattern), like `*email*` or `patient.dob`.
+  - commentKeywords: a text searched in the comment of the column, case insensitive.
+  - valueDetectors: a regular expression that the sampled values must match. At least MinMatchFraction of the non-null
+    values must match it, and the confidence of the rule is scaled by the fraction that matched.
+*/
+type classificationRule struct {
+	Pattern          string  `json:"pattern"`
+	Classification   string  `json:"classification"`
+	Category         string  `json:"category"`
+	Confidence       float64 `json:"confidence"`
+	MinMatchFraction float64 `json:"minMatchFraction,omitempty"`
+
+	regexp *regexp.Regexp
+}
+
+/*
+The rules to classify columns, as read from a JSON file with the same structure.
+
+Columns only get the classifications whose combined confidence is at least MinConfidence. The confidence of a
+classification supported by several rules is 1 - (1 - c1) * (1 - c2) * ..., so weak evidence, like a column of dates,
+becomes strong when combined with other evidence, like a name with `birth`.
+*/
+type classificationConfig struct {
+	MinConfidence   float64              `json:"minConfidence"`
+	NameRules       []classificationRule `json:"nameRules"`
+	CommentKeywords []classificationRule `json:"commentKeywords"`
+	ValueDetectors  []classificationRule `json:"valueDetectors"`
+}
+
+// Rules used when no configuration file is given
+var defaultClassificationConfig = classificationConfig{
+	MinConfidence: 0.4,
+	NameRules: []classificationRule{
+		{Pattern: "*email*", Classification: "email", Category: piiCategory, Confidence: 0.8},
+		{Pattern: "*first_name", Classification: "person_name", Category: piiCategory, Confidence: 0.7},
+		{Pattern: "*last_name", Classification: "person_name", Category: piiCategory, Confidence: 0.7},
+		{Pattern: "*surname", Classification: "person_name", Category: piiCategory, Confidence: 0.7},
+		{Pattern: "*full_name", Classification: "person_name", Category: piiCategory, Confidence: 0.7},
+		{Pattern: "patient_name", Classification: "person_name", Category: piiCategory, Confidence: 0.7},
+		{Pattern: "*birth*", Classification: "date_of_birth", Category: phiCategory, Confidence: 0.8},
+		{Pattern: "dob", Classification: "date_of_birth", Category: phiCategory, Confidence: 0.8},
+		{Pattern: "*_dob", Classification: "date_of_birth", Category: phiCategory, Confidence: 0.8},
+		{Pattern: "*phone*", Classification: "phone_number", Category: piiCategory, Confidence: 0.7},
+		{Pattern: "*mobile*", Classification: "phone_number", Category: piiCategory, Confidence: 0.6},
+		{Pattern: "*address*", Classification: "address", Category: piiCategory, Confidence: 0.6},
+		{Pattern: "*street*", Classification: "address", Category: piiCategory, Confidence: 0.6},
+		{Pattern: "*postcode*", Classification: "address", Category: piiCategory, Confidence: 0.6},
+		{Pattern: "*postal_code*", Classification: "address", Category: piiCategory, Confidence: 0.6},
+		{Pattern: "*zip_code*", Classification: "address", Category: piiCategory, Confidence: 0.6},
+		{Pattern: "*ssn*", Classification: "national_id", Category: piiCategory, Confidence: 0.7},
+		{Pattern: "*national_id*", Classification: "national_id", Category: piiCategory, Confidence: 0.8},
+		{Pattern: "*passport*", Classification: "national_id", Category: piiCategory, Confidence: 0.8},
+		{Pattern: "*nhs_number*", Classification: "hospital_id", Category: phiCategory, Confidence: 0.8},
+		{Pattern: "*mrn*", Classification: "hospital_id", Category: phiCategory, Confidence: 0.7},
+		{Pattern: "*medical_record*", Classification: "hospital_id", Category: phiCategory, Confidence: 0.8},
+		{Pattern: "*hospital_id*", Classification: "hospital_id", Category: phiCategory, Confidence: 0.8},
+		{Pattern: "*hospital_number*", Classification: "hospital_id", Category: phiCategory, Confidence: 0.8},
+		{Pattern: "*patient_id*", Classification: "patient_identifier", Category: phiCategory, Confidence: 0.6},
+		{Pattern: "*ip_address*", Classification: "ip_address", Category: piiCategory, Confidence: 0.7},
+	},
+	CommentKeywords: []classificationRule{
+		{Pattern: "email", Classification: "email", Category: piiCategory, Confidence: 0.5},
+		{Pattern: "date of birth", Classification: "date_of_birth", Category: phiCategory, Confidence: 0.6},
+		{Pattern: "medical record", Classification: "hospital_id", Category: phiCategory, Confidence: 0.6},
+		{Pattern: "hospital number", Classification: "hospital_id", Category: phiCategory, Confidence: 0.6},
+		{Pattern: "patient identifier", Classification: "patient_identifier", Category: phiCategory, Confidence: 0.5},
+		{Pattern: "phone", Classification: "phone_number", Category: piiCategory, Confidence: 0.4},
+		{Pattern: "address", Classification: "address", Category: piiCategory, Confidence: 0.4},
+	},
+	ValueDetectors: []classificationRule{
+		{Pattern: `^[^@\s]+@[^@\s]+\.[^@\s]+$`, Classification: "email", Category: piiCategory, Confidence: 0.9},
+		// Any date looks like a date of birth, so this only adds to other evidence
+		{Pattern: `^(19|20)\d{2}-\d{2}-\d{2}$`, Classification: "date_of_birth", Category: phiCategory, Confidence: 0.3},
+		// Medical record numbers and NHS numbers
+		{Pattern: `^(MRN|HN)[-: ]?\d{5,10}$|^\d{3} \d{3} \d{4}$`, Classification: "hospital_id", Category: phiCategory,
+			Confidence: 0.6},
+		{Pattern: `^\+\d[\d\s().-]{7,}\d$|^\(?\d{3}\)?[\s.-]\d{3}[\s.-]\d{4}$`, Classification: "phone_number",
+			Category: piiCategory, Confidence: 0.6},
+		{Pattern: `^\d{3}-\d{2}-\d{4}$`, Classification: "national_id", Category: piiCategory, Confidence: 0.7},
+		{Pattern: `^(\d{1,3}\.){3}\d{1,3}$`, Classification: "ip_address", Category: piiCategory, Confidence: 0.7},
+	},
+}
+
+/*
+Populates the columns of the entities in `dataMap` with the kinds of sensitive data they may contain, using the rules
+in `input.ClassificationConfigFile` or the default ones.
+
+Names and comments of all columns are checked. When `input.ClassificationSamples` is positive, the values of the
+columns of tables, partitioned tables and materialized views in that number of rows are also checked.
+*/
+func populateClassifications(
+	dataMap map[string]map[string]model.Entity, dBConnector connector.DBConnector, input connector.Input, db *sql.DB) {
+	config := defaultClassificationConfig
+	if input.ClassificationConfigFile != "" {
+		config = readClassificationConfig(input.ClassificationConfigFile)
+	}
+	for i := range config.ValueDetectors {
+		config.ValueDetectors[i].regexp = regexp.MustCompile(config.ValueDetectors[i].Pattern)
+	}
+
+	// map with schema name --> entity name --> values of each column, in the same order as the columns
+	sampledValues := make(map[string]map[string][][]*string)
+	if input.ClassificationSamples > 0 {
+		for _, e := range getEntitiesWithStorage(dataMap, false) {
+			if values := sampleColumnValues(dataMap[e.SchemaName][e.EntityName], dBConnector, input, db); values != nil {
+				if _, schemaExists := sampledValues[e.SchemaName]; !schemaExists {
+					sampledValues[e.SchemaName] = make(map[string][][]*string)
+				}
+				sampledValues[e.SchemaName][e.EntityName] = values
+			}
+		}
+	}
+
+	for schemaName, entityMap := range dataMap {
+		for entityName, entity := range entityMap {
+			values := sampledValues[schemaName][entityName]
+			for i := range entity.Columns {
+				var columnValues []*string
+				if values != nil {
+					columnValues = values[i]
+				}
+				entity.Columns[i].Classifications = classifyColumn(entity.Columns[i], columnValues, config)
+			}
+			dataMap[schemaName][entityName] = entity
+		}
+	}
+}
+
+// Reads the rules to classify columns from a JSON file. Invalid files stop the program, as ignoring them could leave
+// sensitive columns unclassified
+func readClassificationConfig(fileName string) classificationConfig {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		log.Fatal("Could not read the classification rules. Error: ", err)
+	}
+	var config classificationConfig
+	if err := json.Unmarshal(content, &config); err != nil {
+		log.Fatalf("Invalid classification rules in %s. Error: %s", fileName, err)
+	}
+	for _, rule := range config.ValueDetectors {
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			log.Fatalf("Invalid regular expression [%s] in %s. Error: %s", rule.Pattern, fileName, err)
+		}
+	}
+	return config
+}
+
+// Returns the values of each column of an entity in the sampled rows, in the same order as its columns, or nil if the
+// rows cannot be read
+func sampleColumnValues(
+	entity model.Entity, dBConnector connector.DBConnector, input connector.Input, db *sql.DB) [][]*string {
+	if len(entity.Columns) == 0 {
+		return nil
+	}
+	columnNames := make([]string, 0, len(entity.Columns))
+	for _, c := range entity.Columns {
+		columnNames = append(columnNames, c.Name)
+	}
+	schemaName, entityName := getEntityQueryNames(entity)
+	queryStatement := dBConnector.GetSampleRowsQueryStatement(
+		schemaName, entityName, getColumnQueryNames(entity, columnNames), 0, input.ClassificationSamples)
+	rows, err := getSampleRowsList(queryStatement, len(columnNames), db)
+	if err != nil {
+		log.Printf("Could not sample the values of %s.%s. Error: %s", entity.SchemaName, entity.Name, err.Error())
+		return nil
+	}
+
+	values := make([][]*string, len(columnNames))
+	for _, row := range rows {
+		for i := range row {
+			values[i] = append(values[i], row[i])
+		}
+	}
+	return values
+}
+
+// Returns the classifications of a column with at least the minimum confidence of `config`, sorted by confidence.
+// `values` are the sampled values of the column, if any
+func classifyColumn(column model.Column, values []*string, config classificationConfig) []model.Classification {
+	// map with classification name --> classification
+	classifications := make(map[string]*model.Classification)
+	addEvidence := func(rule classificationRule, confidence float64, evidence string) {
+		classification, classificationExists := classifications[rule.Classification]
+		if !classificationExists {
+			classification = &model.Classification{Name: rule.Classification, Category: rule.Category}
+			classifications[rule.Classification] = classification
+		}
+		classification.Confidence = 1 - (1-classification.Confidence)*(1-confidence)
+		classification.Evidence = append(classification.Evidence, evidence)
+	}
+
+	for _, rule := range config.NameRules {
+		if matchesColumnPattern([]string{rule.Pattern}, column.SchemaName, column.EntityName, column.Name) {
+			addEvidence(rule, rule.Confidence, "name matches "+rule.Pattern)
+		}
+	}
+	comment := strings.ToLower(column.Comment)
+	for _, rule := range config.CommentKeywords {
+		if comment != "" && strings.Contains(comment, strings.ToLower(rule.Pattern)) {
+			addEvidence(rule, rule.Confidence, "comment contains "+rule.Pattern)
+		}
+	}
+
+	nonNullValues := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != nil {
+			nonNullValues = append(nonNullValues, *v)
+		}
+	}
+	for _, rule := range config.ValueDetectors {
+		if len(nonNullValues) == 0 {
+			break
+		}
+		matchCount := 0
+		for _, v := range nonNullValues {
+			if rule.regexp.MatchString(v) {
+				matchCount++
+			}
+		}
+		minMatchFraction := rule.MinMatchFraction
+		if minMatchFraction <= 0 {
+			minMatchFraction = defaultMinMatchFraction
+		}
+		fraction := float64(matchCount) / float64(len(nonNullValues))
+		if matchCount > 0 && fraction >= minMatchFraction {
+			addEvidence(rule, rule.Confidence*fraction,
+				fmt.Sprintf("%.0f%% of %d sampled values match %s", fraction*100, len(nonNullValues), rule.Pattern))
+		}
+	}
+
+	var result []model.Classification
+	for _, c := range classifications {
+		if c.Confidence >= config.MinConfidence {
+			result = append(result, *c)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Confidence != result[j].Confidence {
+			return result[i].Confidence > result[j].Confidence
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
@@ -0,0 +1,257 @@
+package extractor
+
+import (
+	"database/sql"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/PDCMFinder/db-descriptor/pkg/connector"
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
+)
+
+// Maximum number of groups in the length distribution of a column
+const maxLengthBuckets = 10
+
+// A known format of values, recognised by a regular expression
+type valuePattern struct {
+	name   string
+	regexp *regexp.Regexp
+}
+
+// Formats detected in the values of the columns. A value can match several of them (an integer is also a decimal)
+var valuePatterns = []valuePattern{
+	{"uuid", regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)},
+	{"integer", regexp.MustCompile(`^[+-]?\d+$`)},
+	{"decimal", regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?$`)},
+	{"date", regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)},
+	{"timestamp", regexp.MustCompile(`^\d{4}-\d{2}-\d{2}[ T]\d{2}:\d{2}(:\d{2}(\.\d+)?)?([+-]\d{2}(:?\d{2})?|Z)?$`)},
+	{"email", regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)},
+	{"url", regexp.MustCompile(`^(https?|ftp)://\S+$`)},
+	// Identifiers of terms of ontologies, like NCIT:C3262, HP:0001250 or UBERON_0000955
+	{"ontology_id", regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*[:_][A-Za-z]?\d+$`)},
+}
+
+/*
+Profiles the contents of the columns of the tables, partitioned tables and materialized views in `dataMap`.
+
+The rows of each entity are sampled as configured in `input`, and the profile of each column is computed from the
+sample. Up to `input.ProfileConcurrency` entities are profiled at the same time. Entities whose sample cannot be read
+are skipped.
+*/
+func populateColumnProfiles(
+	dataMap map[string]map[string]model.Entity, dBConnector connector.DBConnector, input connector.Input, db *sql.DB) {
+	entities := getEntitiesWithStorage(dataMap, false)
+	log.Println("Profiling", len(entities), "entities")
+
+	concurrency := input.ProfileConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	// Each entity writes its profiles in its own position, so no lock is needed
+	profiles := make([][]*model.ColumnProfile, len(entities))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, e := range entities {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, entity model.Entity) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			profiles[i] = profileEntity(entity, dBConnector, input, db)
+		}(i, dataMap[e.SchemaName][e.EntityName])
+	}
+	wg.Wait()
+
+	for i, e := range entities {
+		if profiles[i] == nil {
+			continue
+		}
+		entity := dataMap[e.SchemaName][e.EntityName]
+		for j := range entity.Columns {
+			entity.Columns[j].Profile = profiles[i][j]
+		}
+		dataMap[e.SchemaName][e.EntityName] = entity
+	}
+}
+
+// Returns the profiles of the columns of an entity, in the same order as its columns, or nil if the sample of rows
+// cannot be read
+func profileEntity(
+	entity model.Entity, dBConnector connector.DBConnector, input connector.Input, db *sql.DB) []*model.ColumnProfile {
+	if len(entity.Columns) == 0 {
+		return nil
+	}
+	columnNames := make([]string, 0, len(entity.Columns))
+	for _, c := range entity.Columns {
+		columnNames = append(columnNames, c.Name)
+	}
+
+	schemaName, entityName := getEntityQueryNames(entity)
+	queryStatement := dBConnector.GetSampleRowsQueryStatement(schemaName, entityName,
+		getColumnQueryNames(entity, columnNames), input.ProfileSamplePercent, input.ProfileSampleRows)
+	rows, err := getSampleRowsList(queryStatement, len(columnNames), db)
+	if err != nil {
+		log.Printf("Could not profile %s.%s. Error: %s", entity.SchemaName, entity.Name, err.Error())
+		return nil
+	}
+
+	profiles := make([]*model.ColumnProfile, 0, len(entity.Columns))
+	for i, c := range entity.Columns {
+		values := make([]*string, 0, len(rows))
+		for _, row := range rows {
+			values = append(values, row[i])
+		}
+		profile := profileColumn(values, isNumericDataType(c.DataType), input.ProfileTopValues)
+		profiles = append(profiles, &profile)
+	}
+	return profiles
+}
+
+// Computes the profile of the values of a column. Nulls are nil. When `numeric` is true, values are compared as
+// numbers to find the minimum and maximum
+func profileColumn(values []*string, numeric bool, topValues int) model.ColumnProfile {
+	profile := model.ColumnProfile{
+		SampledRows:        int64(len(values)),
+		LengthDistribution: make([]model.LengthBucket, 0),
+		TopValues:          make([]model.ValueFrequency, 0),
+		Patterns:           make([]model.ValueFrequency, 0)}
+
+	// map with value --> number of rows with the value
+	counts := make(map[string]int64)
+	// map with length --> number of values with the length
+	lengths := make(map[int]int64)
+	totalLength := 0
+	for _, v := range values {
+		if v == nil {
+			profile.NullCount++
+			continue
+		}
+		value := *v
+		if counts[value] == 0 {
+			if profile.DistinctCount == 0 || isLessThan(value, profile.Min, numeric) {
+				profile.Min = value
+			}
+			if profile.DistinctCount == 0 || isLessThan(profile.Max, value, numeric) {
+				profile.Max = value
+			}
+			profile.DistinctCount++
+		}
+		counts[value]++
+		length := utf8.RuneCountInString(value)
+		lengths[length]++
+		totalLength += length
+	}
+
+	nonNullCount := profile.SampledRows - profile.NullCount
+	if nonNullCount == 0 {
+		return profile
+	}
+
+	profile.AverageLength = float64(totalLength) / float64(nonNullCount)
+	profile.LengthDistribution = getLengthDistribution(lengths)
+	profile.MinLength = profile.LengthDistribution[0].MinLength
+	profile.MaxLength = profile.LengthDistribution[len(profile.LengthDistribution)-1].MaxLength
+
+	profile.TopValues = getTopValues(counts, nonNullCount, topValues)
+	// Patterns are evaluated once per distinct value
+	patternCounts := make([]int64, len(valuePatterns))
+	for value, count := range counts {
+		for i, p := range valuePatterns {
+			if p.regexp.MatchString(value) {
+				patternCounts[i] += count
+			}
+		}
+	}
+	for i, p := range valuePatterns {
+		if patternCounts[i] > 0 {
+			profile.Patterns = append(profile.Patterns, model.ValueFrequency{
+				Value:     p.name,
+				Count:     patternCounts[i],
+				Frequency: float64(patternCounts[i]) / float64(nonNullCount)})
+		}
+	}
+	return profile
+}
+
+// Returns the `n` most frequent values in `counts`, with their frequency relative to `total`. Ties are sorted by value
+func getTopValues(counts map[string]int64, total int64, n int) []model.ValueFrequency {
+	values := make([]model.ValueFrequency, 0, len(counts))
+	for value, count := range counts {
+		values = append(values, model.ValueFrequency{Value: value, Count: count})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+		return values[i].Value < values[j].Value
+	})
+	if n < 0 {
+		n = 0
+	}
+	if len(values) > n {
+		values = values[:n]
+	}
+	for i := range values {
+		values[i].Frequency = float64(values[i].Count) / float64(total)
+	}
+	return values
+}
+
+// Groups the lengths of the values in at most `maxLengthBuckets` groups of the same width
+func getLengthDistribution(lengths map[int]int64) []model.LengthBucket {
+	sortedLengths := make([]int, 0, len(lengths))
+	for length := range lengths {
+		sortedLengths = append(sortedLengths, length)
+	}
+	sort.Ints(sortedLengths)
+
+	minLength := sortedLengths[0]
+	maxLength := sortedLengths[len(sortedLengths)-1]
+	width := 1
+	if len(sortedLengths) > maxLengthBuckets {
+		width = (maxLength - minLength + maxLengthBuckets) / maxLengthBuckets
+	}
+
+	buckets := make([]model.LengthBucket, 0)
+	for _, length := range sortedLengths {
+		bucketStart := minLength + (length-minLength)/width*width
+		last := len(buckets) - 1
+		if last >= 0 && buckets[last].MinLength == bucketStart {
+			buckets[last].Count += lengths[length]
+			continue
+		}
+		bucketEnd := bucketStart + width - 1
+		if bucketEnd > maxLength {
+			bucketEnd = maxLength
+		}
+		buckets = append(buckets, model.LengthBucket{MinLength: bucketStart, MaxLength: bucketEnd, Count: lengths[length]})
+	}
+	return buckets
+}
+
+// Returns true if `a` is less than `b`, comparing them as numbers when `numeric` is true and both are valid numbers
+func isLessThan(a string, b string, numeric bool) bool {
+	if numeric {
+		aNumber, aErr := strconv.ParseFloat(a, 64)
+		bNumber, bErr := strconv.ParseFloat(b, 64)
+		if aErr == nil && bErr == nil {
+			return aNumber < bNumber
+		}
+	}
+	return a < b
+}
+
+// Returns true if the data type (as returned by the columns query) is a number type
+func isNumericDataType(dataType string) bool {
+	baseType := strings.TrimSpace(strings.SplitN(strings.ToLower(dataType), "(", 2)[0])
+	switch baseType {
+	case "smallint", "integer", "bigint", "numeric", "decimal", "real", "double precision":
+		return true
+	}
+	return false
+}
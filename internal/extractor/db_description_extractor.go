@@ -2,11 +2,16 @@
 package extractor
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"math"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/PDCMFinder/db-descriptor/pkg/connector"
 	"github.com/PDCMFinder/db-descriptor/pkg/model"
@@ -16,14 +21,17 @@ import (
 A struct to hold a [connector/DBConnector] and use it to query the database.
 
 Tje `dBConnector` property is a implementation of [connector/DBConnector], specific to a database type (like postgres).
+The `input` property has the options that control which optional parts of the description are extracted.
 */
 type dbDescriptionExtractor struct {
 	dBConnector connector.DBConnector
+	input       connector.Input
 }
 
-// Returns an instance of [dbDescriptionExtractor] after initializing it with a [connector/DBConnector].
-func New(dBConnector connector.DBConnector) dbDescriptionExtractor {
-	instance := dbDescriptionExtractor{dBConnector}
+// Returns an instance of [dbDescriptionExtractor] after initializing it with a [connector/DBConnector] and the input
+// parameters of the program.
+func New(dBConnector connector.DBConnector, input connector.Input) dbDescriptionExtractor {
+	instance := dbDescriptionExtractor{dBConnector, input}
 	return instance
 }
 
@@ -35,19 +43,114 @@ func (d dbDescriptionExtractor) ExtractDescription() model.DatabaseDescription {
 	db, err := d.dBConnector.GetConnection()
 	validateConnection(db, err)
 
+	// Add general information of the database
+	databaseDescription := getDatabaseInformation(d.dBConnector.GetDatabaseQueryStatement(), db)
+	databaseDescription.Extensions, _ = getExtensionsList(d.dBConnector.GetExtensionsQueryStatement(), db)
+	databaseDescription.Settings, _ = getSettingsList(d.dBConnector.GetSettingsQueryStatement(), db)
+
 	// 2-dimensional map with schema name --> entity name --> entity
 	dataMap := make(map[string]map[string]model.Entity)
+	// map with schema name --> schema, to hold the objects of the schemas that are not entities
+	schemaMap := make(map[string]model.Schema)
 	// Add descriptions of entities
 	populateEntities(dataMap, d.dBConnector.GetEntitiesQueryStatement(), db)
 	// Add descriptions of columns
 	populateColumns(dataMap, d.dBConnector.GetColumnsQueryStatement(), db)
 	// Add relations
 	populateRelations(dataMap, d.dBConnector.GetRelationsQueryStatement(), db)
+	// Add check constraints and the domains of values they impose on columns
+	populateCheckConstraints(dataMap, d.dBConnector.GetCheckConstraintsQueryStatement(), db)
+	// Add unique keys
+	populateUniqueKeys(dataMap, d.dBConnector.GetUniqueKeysQueryStatement(), db)
+	// Add the definitions of views and the entities they read
+	populateViewDefinitions(dataMap, d.dBConnector.GetViewDefinitionsQueryStatement(), db)
+	populateViewDependencies(dataMap, d.dBConnector.GetViewDependenciesQueryStatement(), db)
+	// Add the lineage of the columns of views
+	populateColumnLineage(dataMap, d.dBConnector.GetViewColumnUsageQueryStatement(), db)
+	// Add inheritance and partitioning
+	populateInheritance(dataMap, d.dBConnector.GetInheritanceQueryStatement(), db)
+	populatePartitionKeys(dataMap, d.dBConnector.GetPartitionKeysQueryStatement(), db)
+	// Propose the relations that are not declared
+	if d.input.InferRelations {
+		populateInferredRelations(dataMap, d.dBConnector, d.input, db)
+	}
+	// Classify relations and add the incoming references of each entity
+	analyseRelations(dataMap)
+	// Add functions and procedures
+	populateRoutines(schemaMap, d.dBConnector.GetRoutinesQueryStatement(), db)
+	// Add sequences and link them to the columns that own them
+	populateSequences(dataMap, schemaMap, d.dBConnector.GetSequencesQueryStatement(), db)
+	// Add user-defined types and show the values allowed by enums and domains in the columns that use them
+	populateUserDefinedTypes(
+		schemaMap,
+		d.dBConnector.GetUserDefinedTypesQueryStatement(),
+		d.dBConnector.GetUserDefinedTypeMembersQueryStatement(),
+		db)
+	applyUserDefinedTypes(dataMap, schemaMap)
+	// Add triggers
+	populateTriggers(dataMap, d.dBConnector.GetTriggersQueryStatement(), db)
+	eventTriggers, _ := getEventTriggersList(d.dBConnector.GetEventTriggersQueryStatement(), db)
+	// Add owners, privileges and row-level security policies
+	populateSchemas(schemaMap, d.dBConnector.GetSchemasQueryStatement(), db)
+	populateGrants(dataMap, schemaMap, d.dBConnector.GetGrantsQueryStatement(), db)
+	populatePolicies(dataMap, d.dBConnector.GetPoliciesQueryStatement(), db)
+
+	// Add sizes and row counts
+	if d.input.IncludeStorageStats {
+		populateStorageStats(dataMap, d.dBConnector.GetStorageStatsQueryStatement(), db)
+	}
+	if d.input.ExactCounts {
+		populateExactRowCounts(dataMap, d.dBConnector, d.input.ExactCountsBudget, db)
+	}
+	// Add the statistics of the columns
+	if d.input.IncludeColumnStats {
+		populateColumnStats(dataMap, d.dBConnector.GetColumnStatsQueryStatement(), db)
+	}
+	// Add the profiles of the contents of the columns
+	if d.input.Profile {
+		populateColumnProfiles(dataMap, d.dBConnector, d.input, db)
+	}
+	// Add the values of the columns with few distinct values
+	if d.input.CategoryThreshold > 0 {
+		populateCategoricalValues(dataMap, d.dBConnector, d.input, db)
+	}
+	// Add the rows of reference tables and show their codes in the columns that reference them
+	if d.input.DetectReferenceTables || len(d.input.ReferenceTables) > 0 {
+		populateReferenceData(dataMap, d.dBConnector, d.input, db)
+	}
+	// Add the structure of the documents of json columns
+	if d.input.JsonSchemaSamples > 0 {
+		populateJsonSchemas(dataMap, d.dBConnector, d.input, db)
+	}
+	// Add the sets of columns that could be the primary key of the tables without one
+	if d.input.CandidateKeySamples > 0 {
+		populateCandidateKeys(dataMap, d.dBConnector, d.input, db)
+	}
+	// Add example rows
+	if d.input.SampleRows > 0 {
+		populateSampleRows(dataMap, d.dBConnector, d.input, db)
+	}
+	// Add the kinds of sensitive data the columns may contain
+	if d.input.Classify {
+		populateClassifications(dataMap, d.dBConnector, d.input, db)
+	}
+	// Mask the values of sensitive columns once all the values are in the description
+	if len(d.input.MaskingRules) > 0 {
+		maskDescriptionValues(dataMap, newValueMasker(d.input.MaskingRules))
+	}
 
 	defer db.Close()
 
-	schemas := buildSchemeList(dataMap)
-	return model.DatabaseDescription{Schemas: schemas}
+	// Describe the results of queries with the entities they read
+	if len(d.input.QueryFiles) > 0 || len(d.input.QueryTexts) > 0 {
+		databaseDescription.Queries = describeQueries(dataMap, d.dBConnector, d.input, db)
+	}
+	if d.input.CollapsePartitions {
+		collapsePartitions(dataMap)
+	}
+	databaseDescription.Schemas = buildSchemeList(dataMap, schemaMap)
+	databaseDescription.EventTriggers = eventTriggers
+	return databaseDescription
 }
 
 // Populates `dataMap` with the database entities information
@@ -66,59 +169,939 @@ func populateEntities(dataMap map[string]map[string]model.Entity, queryStatement
 	}
 }
 
-// Populates `dataMap` with the database columns information
-func populateColumns(dataMap map[string]map[string]model.Entity, queryStatement string, db *sql.DB) {
-	columns, err := getColumnsList(queryStatement, db)
+// Populates `dataMap` with the database columns information
+func populateColumns(dataMap map[string]map[string]model.Entity, queryStatement string, db *sql.DB) {
+	columns, err := getColumnsList(queryStatement, db)
+	if err == nil {
+		for _, c := range columns {
+			// The entity should exists already. If not, maybe an error could be thrown
+			entity, entityExists := dataMap[c.SchemaName][c.EntityName]
+			if entityExists {
+				entity.Columns = append(entity.Columns, c)
+				dataMap[c.SchemaName][c.EntityName] = entity
+			}
+		}
+	}
+}
+
+func populateRelations(dataMap map[string]map[string]model.Entity, queryStatement string, db *sql.DB) {
+	relations, err := getRelationsList(queryStatement, db)
+	if err == nil {
+		for _, r := range relations {
+			// The entity should exists already. If not, maybe an error could be thrown
+			entity, entityExists := dataMap[r.SchemaName][r.EntityName]
+			if entityExists {
+				entity.Relations = append(entity.Relations, r)
+				dataMap[r.SchemaName][r.EntityName] = entity
+			}
+		}
+	}
+}
+
+// Populates `dataMap` with the check constraints of the entities. Constraints on a single column are also parsed to
+// set the allowed values or range of values of that column
+func populateCheckConstraints(dataMap map[string]map[string]model.Entity, queryStatement string, db *sql.DB) {
+	checkConstraints, err := getCheckConstraintsList(queryStatement, db)
+	if err == nil {
+		for _, c := range checkConstraints {
+			entity, entityExists := dataMap[c.SchemaName][c.EntityName]
+			if entityExists {
+				entity.CheckConstraints = append(entity.CheckConstraints, c)
+				if len(c.ColumnNames) == 1 {
+					applyCheckDomain(&entity, c.ColumnNames[0], c.Expression)
+				}
+				dataMap[c.SchemaName][c.EntityName] = entity
+			}
+		}
+	}
+}
+
+// Populates `dataMap` with the unique keys of the entities
+func populateUniqueKeys(dataMap map[string]map[string]model.Entity, queryStatement string, db *sql.DB) {
+	uniqueKeys, err := getUniqueKeysList(queryStatement, db)
+	if err == nil {
+		for _, k := range uniqueKeys {
+			entity, entityExists := dataMap[k.SchemaName][k.EntityName]
+			if entityExists {
+				entity.UniqueKeys = append(entity.UniqueKeys, k)
+				dataMap[k.SchemaName][k.EntityName] = entity
+			}
+		}
+	}
+}
+
+// Populates `dataMap` with the definitions of the views
+func populateViewDefinitions(dataMap map[string]map[string]model.Entity, queryStatement string, db *sql.DB) {
+	rows, err := db.Query(queryStatement)
+	if err == nil {
+		for rows.Next() {
+			var view_schema string
+			var view_name string
+			var view_definition string
+
+			err := rows.Scan(&view_schema, &view_name, &view_definition)
+			if err != nil {
+				panic(err)
+			}
+
+			schemaName := strings.ToLower(view_schema)
+			viewName := strings.ToLower(view_name)
+			entity, entityExists := dataMap[schemaName][viewName]
+			if entityExists {
+				entity.ViewDefinition = strings.TrimSpace(view_definition)
+				dataMap[schemaName][viewName] = entity
+			}
+		}
+	}
+}
+
+// Populates `dataMap` with the dependencies between views and the entities they read. Both ends are updated: the view
+// gets the entity in its `Dependencies` and the entity gets the view in its `Dependents`
+func populateViewDependencies(dataMap map[string]map[string]model.Entity, queryStatement string, db *sql.DB) {
+	dependencies, err := getViewDependenciesList(queryStatement, db)
+	if err == nil {
+		for _, d := range dependencies {
+			view, viewExists := dataMap[d.view.SchemaName][d.view.EntityName]
+			if viewExists {
+				view.Dependencies = append(view.Dependencies, d.table)
+				dataMap[d.view.SchemaName][d.view.EntityName] = view
+			}
+			table, tableExists := dataMap[d.table.SchemaName][d.table.EntityName]
+			if tableExists {
+				table.Dependents = append(table.Dependents, d.view)
+				dataMap[d.table.SchemaName][d.table.EntityName] = table
+			}
+		}
+	}
+}
+
+// Populates the columns of the views in `dataMap` with the columns they derive from
+func populateColumnLineage(dataMap map[string]map[string]model.Entity, queryStatement string, db *sql.DB) {
+	usages, err := getViewColumnUsageList(queryStatement, db)
+	if err == nil {
+		// Group the columns read by each view
+		usagesByView := make(map[string]map[string][]model.ColumnLineage)
+		for _, u := range usages {
+			if _, schemaExists := usagesByView[u.viewSchema]; !schemaExists {
+				usagesByView[u.viewSchema] = make(map[string][]model.ColumnLineage)
+			}
+			usagesByView[u.viewSchema][u.viewName] = append(usagesByView[u.viewSchema][u.viewName], u.source)
+		}
+
+		for schemaName, viewUsages := range usagesByView {
+			for viewName, sources := range viewUsages {
+				view, viewExists := dataMap[schemaName][viewName]
+				if !viewExists || view.ViewDefinition == "" {
+					continue
+				}
+				for position, lineage := range buildViewColumnLineage(view, sources) {
+					view.Columns[position].Lineage = lineage
+				}
+				dataMap[schemaName][viewName] = view
+			}
+		}
+	}
+}
+
+// Populates `dataMap` with the inheritance relationships between entities. Both ends are updated: the child gets the
+// parent in its `Parents` and the parent gets the child in its `Children`
+func populateInheritance(dataMap map[string]map[string]model.Entity, queryStatement string, db *sql.DB) {
+	inheritances, err := getInheritanceList(queryStatement, db)
+	if err == nil {
+		for _, i := range inheritances {
+			child, childExists := dataMap[i.child.SchemaName][i.child.EntityName]
+			if childExists {
+				child.Parents = append(child.Parents, i.parent)
+				child.PartitionBound = i.partitionBound
+				dataMap[i.child.SchemaName][i.child.EntityName] = child
+			}
+			parent, parentExists := dataMap[i.parent.SchemaName][i.parent.EntityName]
+			if parentExists {
+				parent.Children = append(parent.Children, i.child)
+				dataMap[i.parent.SchemaName][i.parent.EntityName] = parent
+			}
+		}
+	}
+}
+
+// Populates the partitioned tables in `dataMap` with their partition strategy and key
+func populatePartitionKeys(dataMap map[string]map[string]model.Entity, queryStatement string, db *sql.DB) {
+	rows, err := db.Query(queryStatement)
+	if err == nil {
+		for rows.Next() {
+			var table_schema string
+			var table_name string
+			var partition_strategy string
+			var partition_key string
+
+			err := rows.Scan(&table_schema, &table_name, &partition_strategy, &partition_key)
+			if err != nil {
+				panic(err)
+			}
+
+			schemaName := strings.ToLower(table_schema)
+			tableName := strings.ToLower(table_name)
+			entity, entityExists := dataMap[schemaName][tableName]
+			if entityExists {
+				entity.PartitionStrategy = partition_strategy
+				entity.PartitionKey = partition_key
+				dataMap[schemaName][tableName] = entity
+			}
+		}
+	}
+}
+
+// Moves the partitions in `dataMap` into the `Partitions` of their partitioned table. Partitions of partitions are
+// nested in the same way. Partitions whose parent is not described are kept as they are
+func collapsePartitions(dataMap map[string]map[string]model.Entity) {
+	isNested := func(entity model.Entity) bool {
+		if entity.EntityType != model.Partition || len(entity.Parents) != 1 {
+			return false
+		}
+		_, parentExists := dataMap[entity.Parents[0].SchemaName][entity.Parents[0].EntityName]
+		return parentExists
+	}
+
+	var attachPartitions func(entity model.Entity) model.Entity
+	attachPartitions = func(entity model.Entity) model.Entity {
+		for _, c := range entity.Children {
+			child, childExists := dataMap[c.SchemaName][c.EntityName]
+			if childExists && isNested(child) {
+				entity.Partitions = append(entity.Partitions, attachPartitions(child))
+			}
+		}
+		return entity
+	}
+
+	nested := make([]model.EntityReference, 0)
+	roots := make([]model.Entity, 0)
+	for _, entityMap := range dataMap {
+		for _, entity := range entityMap {
+			if isNested(entity) {
+				nested = append(nested, model.EntityReference{SchemaName: entity.SchemaName, EntityName: entity.Name})
+			} else if len(entity.Children) > 0 {
+				roots = append(roots, entity)
+			}
+		}
+	}
+
+	for _, root := range roots {
+		dataMap[root.SchemaName][root.Name] = attachPartitions(root)
+	}
+	for _, n := range nested {
+		delete(dataMap[n.SchemaName], n.EntityName)
+	}
+}
+
+// Populates `schemaMap` with the sequences of each schema, and the columns in `dataMap` that own a sequence with a link
+// to it
+func populateSequences(
+	dataMap map[string]map[string]model.Entity, schemaMap map[string]model.Schema, queryStatement string, db *sql.DB) {
+	sequences, err := getSequencesList(queryStatement, db)
+	if err == nil {
+		for _, s := range sequences {
+			schema := schemaMap[s.SchemaName]
+			schema.Sequences = append(schema.Sequences, s)
+			schemaMap[s.SchemaName] = schema
+
+			entity, entityExists := dataMap[s.OwnerSchemaName][s.OwnerEntityName]
+			if entityExists {
+				for i := range entity.Columns {
+					if entity.Columns[i].Name == s.OwnerColumnName {
+						entity.Columns[i].SequenceSchemaName = s.SchemaName
+						entity.Columns[i].SequenceName = s.Name
+					}
+				}
+				dataMap[s.OwnerSchemaName][s.OwnerEntityName] = entity
+			}
+		}
+	}
+}
+
+// Populates `schemaMap` with the user-defined types of each schema, including their enum labels, attributes and
+// constraints
+func populateUserDefinedTypes(
+	schemaMap map[string]model.Schema, typesQueryStatement string, membersQueryStatement string, db *sql.DB) {
+	types, err := getUserDefinedTypesList(typesQueryStatement, db)
+	if err != nil {
+		return
+	}
+
+	// map with schema name --> type name --> position of the type in `types`
+	typeIndex := make(map[string]map[string]int)
+	for i, t := range types {
+		if _, schemaExists := typeIndex[t.SchemaName]; !schemaExists {
+			typeIndex[t.SchemaName] = make(map[string]int)
+		}
+		typeIndex[t.SchemaName][t.Name] = i
+	}
+
+	rows, err := db.Query(membersQueryStatement)
+	if err == nil {
+		for rows.Next() {
+			var type_schema string
+			var type_name string
+			var member_kind string
+			var member_name string
+			var member_value string
+			var position int
+
+			err := rows.Scan(&type_schema, &type_name, &member_kind, &member_name, &member_value, &position)
+			if err != nil {
+				panic(err)
+			}
+
+			schemaName := strings.ToLower(type_schema)
+			i, typeExists := typeIndex[schemaName][strings.ToLower(type_name)]
+			if !typeExists {
+				continue
+			}
+			t := &types[i]
+			switch member_kind {
+			case "label":
+				t.EnumLabels = append(t.EnumLabels, member_name)
+			case "attribute":
+				t.Attributes = append(t.Attributes, model.TypeAttribute{Name: strings.ToLower(member_name), DataType: member_value})
+			case "constraint":
+				t.Constraints = append(t.Constraints, model.CheckConstraint{
+					SchemaName: schemaName,
+					EntityName: t.Name,
+					Name:       strings.ToLower(member_name),
+					Expression: member_value})
+				// The expression of a domain constraint refers to the value as VALUE, so the column name is not checked
+				if domain, ok := deriveCheckDomain(member_value); ok {
+					if domain.allowedValues != nil && t.AllowedValues == nil {
+						t.AllowedValues = domain.allowedValues
+					}
+					if domain.valueRange != nil {
+						t.ValueRange = mergeValueRanges(t.ValueRange, domain.valueRange)
+					}
+				}
+			}
+		}
+	}
+
+	for _, t := range types {
+		schema := schemaMap[t.SchemaName]
+		schema.Types = append(schema.Types, t)
+		schemaMap[t.SchemaName] = schema
+	}
+}
+
+// Sets the values allowed by enums and domains on the columns in `dataMap` whose type is one of them, unless the
+// column already has a domain from its own check constraints
+func applyUserDefinedTypes(dataMap map[string]map[string]model.Entity, schemaMap map[string]model.Schema) {
+	// map with schema name --> type name --> type
+	typeMap := make(map[string]map[string]model.UserDefinedType)
+	for schemaName, schema := range schemaMap {
+		typeMap[schemaName] = make(map[string]model.UserDefinedType)
+		for _, t := range schema.Types {
+			typeMap[schemaName][t.Name] = t
+		}
+	}
+
+	for _, entityMap := range dataMap {
+		for _, entity := range entityMap {
+			for i := range entity.Columns {
+				column := &entity.Columns[i]
+				t, typeExists := typeMap[column.TypeSchemaName][column.TypeName]
+				if !typeExists {
+					continue
+				}
+				if column.AllowedValues == nil {
+					if t.Kind == model.EnumType {
+						column.AllowedValues = t.EnumLabels
+					} else {
+						column.AllowedValues = t.AllowedValues
+					}
+				}
+				if column.ValueRange == nil {
+					column.ValueRange = t.ValueRange
+				}
+			}
+		}
+	}
+}
+
+// Populates `dataMap` with the triggers of the entities
+func populateTriggers(dataMap map[string]map[string]model.Entity, queryStatement string, db *sql.DB) {
+	triggers, err := getTriggersList(queryStatement, db)
+	if err == nil {
+		for _, t := range triggers {
+			entity, entityExists := dataMap[t.SchemaName][t.EntityName]
+			if entityExists {
+				entity.Triggers = append(entity.Triggers, t)
+				dataMap[t.SchemaName][t.EntityName] = entity
+			}
+		}
+	}
+}
+
+// Populates `schemaMap` with the information of the schemas themselves, like their owner and comment
+func populateSchemas(schemaMap map[string]model.Schema, queryStatement string, db *sql.DB) {
+	rows, err := db.Query(queryStatement)
+	if err == nil {
+		for rows.Next() {
+			var schema_name string
+			var owner string
+			var comment string
+
+			err := rows.Scan(&schema_name, &owner, &comment)
+			if err != nil {
+				panic(err)
+			}
+
+			schemaName := strings.ToLower(schema_name)
+			schema := schemaMap[schemaName]
+			schema.Owner = owner
+			schema.Comment = comment
+			schemaMap[schemaName] = schema
+		}
+	}
+}
+
+// Populates `schemaMap` and `dataMap` with the privileges granted on the schemas and on the entities and their columns
+func populateGrants(
+	dataMap map[string]map[string]model.Entity, schemaMap map[string]model.Schema, queryStatement string, db *sql.DB) {
+	grants, err := getGrantsList(queryStatement, db)
+	if err == nil {
+		for _, g := range grants {
+			if g.EntityName == "" {
+				schema := schemaMap[g.SchemaName]
+				schema.Grants = append(schema.Grants, g)
+				schemaMap[g.SchemaName] = schema
+				continue
+			}
+			entity, entityExists := dataMap[g.SchemaName][g.EntityName]
+			if entityExists {
+				entity.Grants = append(entity.Grants, g)
+				dataMap[g.SchemaName][g.EntityName] = entity
+			}
+		}
+	}
+}
+
+// Populates `dataMap` with the row-level security policies of the entities
+func populatePolicies(dataMap map[string]map[string]model.Entity, queryStatement string, db *sql.DB) {
+	policies, err := getPoliciesList(queryStatement, db)
+	if err == nil {
+		for _, p := range policies {
+			entity, entityExists := dataMap[p.SchemaName][p.EntityName]
+			if entityExists {
+				entity.Policies = append(entity.Policies, p)
+				dataMap[p.SchemaName][p.EntityName] = entity
+			}
+		}
+	}
+}
+
+// Populates `dataMap` with the storage statistics of the entities
+func populateStorageStats(dataMap map[string]map[string]model.Entity, queryStatement string, db *sql.DB) {
+	rows, err := db.Query(queryStatement)
+	if err == nil {
+		for rows.Next() {
+			var table_schema string
+			var table_name string
+			var estimated_rows sql.NullInt64
+			var table_size int64
+			var indexes_size int64
+			var toast_size int64
+			var total_size int64
+			var live_tuples int64
+			var dead_tuples int64
+			var last_vacuum sql.NullTime
+			var last_autovacuum sql.NullTime
+			var last_analyze sql.NullTime
+			var last_autoanalyze sql.NullTime
+
+			err := rows.Scan(
+				&table_schema,
+				&table_name,
+				&estimated_rows,
+				&table_size,
+				&indexes_size,
+				&toast_size,
+				&total_size,
+				&live_tuples,
+				&dead_tuples,
+				&last_vacuum,
+				&last_autovacuum,
+				&last_analyze,
+				&last_autoanalyze)
+			if err != nil {
+				panic(err)
+			}
+
+			schemaName := strings.ToLower(table_schema)
+			tableName := strings.ToLower(table_name)
+			entity, entityExists := dataMap[schemaName][tableName]
+			if !entityExists {
+				continue
+			}
+
+			var storage model.StorageStats = model.StorageStats{
+				TableSize:       table_size,
+				IndexesSize:     indexes_size,
+				ToastSize:       toast_size,
+				TotalSize:       total_size,
+				LiveTuples:      live_tuples,
+				DeadTuples:      dead_tuples,
+				LastVacuum:      getTimePointer(last_vacuum),
+				LastAutovacuum:  getTimePointer(last_autovacuum),
+				LastAnalyze:     getTimePointer(last_analyze),
+				LastAutoanalyze: getTimePointer(last_autoanalyze)}
+			if estimated_rows.Valid {
+				storage.EstimatedRowCount = &estimated_rows.Int64
+			}
+			entity.Storage = &storage
+			dataMap[schemaName][tableName] = entity
+		}
+	}
+}
+
+/*
+Counts the rows of the tables, partitioned tables, partitions and materialized views in `dataMap`.
+
+Counting can be slow on big tables, so all the counts together are limited to `budget` (no limit if it is not
+positive). When the budget is exhausted the running count is cancelled, and the entities not counted yet are left
+without an exact count.
+*/
+func populateExactRowCounts(
+	dataMap map[string]map[string]model.Entity, dBConnector connector.DBConnector, budget time.Duration, db *sql.DB) {
+	ctx := context.Background()
+	if budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, budget)
+		defer cancel()
+	}
+
+	// The order is stable, so the same entities are counted when the budget is exhausted
+	entities := getEntitiesWithStorage(dataMap, true)
+	for i, e := range entities {
+		var row_count int64
+		schemaName, entityName := getEntityQueryNames(dataMap[e.SchemaName][e.EntityName])
+		err := db.QueryRowContext(ctx, dBConnector.GetRowCountQueryStatement(schemaName, entityName)).Scan(&row_count)
+		if err != nil {
+			if ctx.Err() != nil {
+				log.Printf("Time budget for exact counts exhausted. %d of %d entities were not counted", len(entities)-i,
+					len(entities))
+				return
+			}
+			log.Printf("Could not count the rows of %s.%s. Error: %s", e.SchemaName, e.EntityName, err.Error())
+			continue
+		}
+
+		entity := dataMap[e.SchemaName][e.EntityName]
+		if entity.Storage == nil {
+			entity.Storage = &model.StorageStats{}
+		}
+		entity.Storage.ExactRowCount = &row_count
+		dataMap[e.SchemaName][e.EntityName] = entity
+	}
+}
+
+// Populates the columns in `dataMap` with the statistics of the planner about them
+func populateColumnStats(dataMap map[string]map[string]model.Entity, queryStatement string, db *sql.DB) {
+	rows, err := db.Query(queryStatement)
+	if err == nil {
+		for rows.Next() {
+			var table_schema string
+			var table_name string
+			var column_name string
+			var null_fraction float64
+			var distinct_count int64
+			var average_width int
+			var most_common_values sql.NullString
+			var most_common_freqs sql.NullString
+			var histogram_bounds sql.NullString
+			var correlation sql.NullFloat64
+			var row_count int64
+
+			err := rows.Scan(
+				&table_schema,
+				&table_name,
+				&column_name,
+				&null_fraction,
+				&distinct_count,
+				&average_width,
+				&most_common_values,
+				&most_common_freqs,
+				&histogram_bounds,
+				&correlation,
+				&row_count)
+			if err != nil {
+				panic(err)
+			}
+
+			schemaName := strings.ToLower(table_schema)
+			tableName := strings.ToLower(table_name)
+			columnName := strings.ToLower(column_name)
+			entity, entityExists := dataMap[schemaName][tableName]
+			if !entityExists {
+				continue
+			}
+
+			var stats model.ColumnStats = model.ColumnStats{
+				NullFraction:     null_fraction,
+				DistinctCount:    distinct_count,
+				AverageWidth:     average_width,
+				MostCommonValues: make([]model.ValueFrequency, 0),
+				HistogramBounds:  make([]string, 0)}
+			if correlation.Valid {
+				stats.Correlation = &correlation.Float64
+			}
+
+			var values []string
+			var frequencies []float64
+			if most_common_values.Valid && most_common_freqs.Valid &&
+				json.Unmarshal([]byte(most_common_values.String), &values) == nil &&
+				json.Unmarshal([]byte(most_common_freqs.String), &frequencies) == nil {
+				for i := 0; i < len(values) && i < len(frequencies); i++ {
+					// The count is estimated from the number of rows, as the planner only keeps the frequency
+					stats.MostCommonValues = append(stats.MostCommonValues, model.ValueFrequency{
+						Value:     values[i],
+						Frequency: frequencies[i],
+						Count:     int64(math.Round(frequencies[i] * float64(row_count)))})
+				}
+			}
+			if histogram_bounds.Valid {
+				// The bounds are left empty if they cannot be decoded
+				_ = json.Unmarshal([]byte(histogram_bounds.String), &stats.HistogramBounds)
+			}
+
+			for i := range entity.Columns {
+				if entity.Columns[i].Name == columnName {
+					entity.Columns[i].Stats = &stats
+				}
+			}
+			dataMap[schemaName][tableName] = entity
+		}
+	}
+}
+
+// Returns the schema and name of an entity with the case they have in the database, to use them in queries
+func getEntityQueryNames(entity model.Entity) (string, string) {
+	if entity.OriginalName == "" {
+		return entity.SchemaName, entity.Name
+	}
+	return entity.OriginalSchemaName, entity.OriginalName
+}
+
+// Returns the names of columns of an entity, given as lowercased names, with the case they have in the database
+func getColumnQueryNames(entity model.Entity, columnNames []string) []string {
+	queryNames := make([]string, 0, len(columnNames))
+	for _, name := range columnNames {
+		queryName := name
+		for _, c := range entity.Columns {
+			if c.Name == name && c.OriginalName != "" {
+				queryName = c.OriginalName
+			}
+		}
+		queryNames = append(queryNames, queryName)
+	}
+	return queryNames
+}
+
+// Returns the tables, partitioned tables and materialized views in `dataMap`, and also the partitions if
+// `includePartitions` is true, sorted by schema and name
+func getEntitiesWithStorage(
+	dataMap map[string]map[string]model.Entity, includePartitions bool) []model.EntityReference {
+	entities := make([]model.EntityReference, 0)
+	for _, entityMap := range dataMap {
+		for _, entity := range entityMap {
+			switch entity.EntityType {
+			case model.Table, model.PartitionedTable, model.MaterializedView:
+			case model.Partition:
+				if !includePartitions {
+					continue
+				}
+			default:
+				continue
+			}
+			entities = append(entities, model.EntityReference{
+				SchemaName: entity.SchemaName, EntityName: entity.Name, EntityType: entity.EntityType})
+		}
+	}
+	sort.Slice(entities, func(i, j int) bool {
+		if entities[i].SchemaName != entities[j].SchemaName {
+			return entities[i].SchemaName < entities[j].SchemaName
+		}
+		return entities[i].EntityName < entities[j].EntityName
+	})
+	return entities
+}
+
+// Populates `schemaMap` with the functions and procedures of each schema
+func populateRoutines(schemaMap map[string]model.Schema, queryStatement string, db *sql.DB) {
+	routines, err := getRoutinesList(queryStatement, db)
+	if err == nil {
+		for _, r := range routines {
+			schema := schemaMap[r.SchemaName]
+			schema.Routines = append(schema.Routines, r)
+			schemaMap[r.SchemaName] = schema
+		}
+	}
+}
+
+// Sets the domain derived from a check constraint expression on the column of the entity it refers to
+func applyCheckDomain(entity *model.Entity, columnName string, expression string) {
+	domain, ok := deriveCheckDomain(expression)
+	if !ok || !strings.EqualFold(domain.columnName, columnName) {
+		return
+	}
+	for i := range entity.Columns {
+		column := &entity.Columns[i]
+		if column.Name != columnName {
+			continue
+		}
+		if domain.allowedValues != nil && column.AllowedValues == nil {
+			column.AllowedValues = domain.allowedValues
+		}
+		if domain.valueRange != nil {
+			column.ValueRange = mergeValueRanges(column.ValueRange, domain.valueRange)
+		}
+	}
+}
+
+// Combines the bounds of two ranges of values. Bounds already set in `current` are kept
+func mergeValueRanges(current *model.ValueRange, other *model.ValueRange) *model.ValueRange {
+	if current == nil {
+		return other
+	}
+	merged := *current
+	if merged.Min == "" {
+		merged.Min = other.Min
+		merged.MinInclusive = other.MinInclusive
+	}
+	if merged.Max == "" {
+		merged.Max = other.Max
+		merged.MaxInclusive = other.MaxInclusive
+	}
+	return &merged
+}
+
+// Executes the query to retrieve the general information of the database and returns a `model.DatabaseDescription`
+// with it. The description is empty if the information cannot be retrieved
+func getDatabaseInformation(queryStatement string, db *sql.DB) model.DatabaseDescription {
+	var databaseDescription model.DatabaseDescription
+	rows, err := db.Query(queryStatement)
+	if err == nil {
+		for rows.Next() {
+			err := rows.Scan(
+				&databaseDescription.Name,
+				&databaseDescription.ServerVersion,
+				&databaseDescription.Encoding,
+				&databaseDescription.Collation,
+				&databaseDescription.CharacterType,
+				&databaseDescription.Comment)
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+	return databaseDescription
+}
+
+// Executes the query to retrieve the installed extensions and converts it to a list of `model.Extension`
+func getExtensionsList(queryStatement string, db *sql.DB) ([]model.Extension, error) {
+	rows, err := db.Query(queryStatement)
+	if err == nil {
+		return processExtensionRows(rows), nil
+	} else {
+		return nil, err
+	}
+}
+
+// Executes the query to retrieve the server settings and converts it to a list of `model.Setting`
+func getSettingsList(queryStatement string, db *sql.DB) ([]model.Setting, error) {
+	rows, err := db.Query(queryStatement)
+	if err == nil {
+		return processSettingRows(rows), nil
+	} else {
+		return nil, err
+	}
+}
+
+// Executes the query to retrieve the entities and converts it to a list of `model.Entity`
+func getEntitiesList(queryStatement string, db *sql.DB) ([]model.Entity, error) {
+	rows, err := db.Query(queryStatement)
+	if err == nil {
+		return processEntityRows(rows), nil
+	} else {
+		return nil, err
+	}
+}
+
+// Executes the query to retrieve the columns and converts it to a list of `model.Column`
+func getColumnsList(queryStatement string, db *sql.DB) ([]model.Column, error) {
+	rows, err := db.Query(queryStatement)
+	if err == nil {
+		return processColumnRows(rows), nil
+	} else {
+		return nil, err
+	}
+}
+
+func getRelationsList(queryStatement string, db *sql.DB) ([]model.Relation, error) {
+	rows, err := db.Query(queryStatement)
+	if err == nil {
+		return processRelationsRows(rows), nil
+	} else {
+		return nil, err
+	}
+}
+
+// Executes the query to retrieve the check constraints and converts it to a list of `model.CheckConstraint`
+func getCheckConstraintsList(queryStatement string, db *sql.DB) ([]model.CheckConstraint, error) {
+	rows, err := db.Query(queryStatement)
+	if err == nil {
+		return processCheckConstraintRows(rows), nil
+	} else {
+		return nil, err
+	}
+}
+
+// Executes the query to retrieve the unique keys and converts it to a list of `model.UniqueKey`
+func getUniqueKeysList(queryStatement string, db *sql.DB) ([]model.UniqueKey, error) {
+	rows, err := db.Query(queryStatement)
+	if err == nil {
+		return processUniqueKeyRows(rows), nil
+	} else {
+		return nil, err
+	}
+}
+
+// A dependency between a view and an entity it reads
+type viewDependency struct {
+	view  model.EntityReference
+	table model.EntityReference
+}
+
+// Executes the query to retrieve the view dependencies and converts it to a list of `viewDependency`
+func getViewDependenciesList(queryStatement string, db *sql.DB) ([]viewDependency, error) {
+	rows, err := db.Query(queryStatement)
+	if err == nil {
+		return processViewDependencyRows(rows), nil
+	} else {
+		return nil, err
+	}
+}
+
+// Executes the query to retrieve the columns read by views and converts it to a list of `viewColumnUsage`
+func getViewColumnUsageList(queryStatement string, db *sql.DB) ([]viewColumnUsage, error) {
+	rows, err := db.Query(queryStatement)
+	if err == nil {
+		return processViewColumnUsageRows(rows), nil
+	} else {
+		return nil, err
+	}
+}
+
+// An inheritance relationship between 2 entities
+type inheritance struct {
+	child          model.EntityReference
+	parent         model.EntityReference
+	partitionBound string
+}
+
+// Executes the query to retrieve the inheritance relationships and converts it to a list of `inheritance`
+func getInheritanceList(queryStatement string, db *sql.DB) ([]inheritance, error) {
+	rows, err := db.Query(queryStatement)
+	if err == nil {
+		return processInheritanceRows(rows), nil
+	} else {
+		return nil, err
+	}
+}
+
+// Executes the query to retrieve the routines and converts it to a list of `model.Routine`
+func getRoutinesList(queryStatement string, db *sql.DB) ([]model.Routine, error) {
+	rows, err := db.Query(queryStatement)
+	if err == nil {
+		return processRoutineRows(rows), nil
+	} else {
+		return nil, err
+	}
+}
+
+// Executes the query to retrieve the triggers and converts it to a list of `model.Trigger`
+func getTriggersList(queryStatement string, db *sql.DB) ([]model.Trigger, error) {
+	rows, err := db.Query(queryStatement)
+	if err == nil {
+		return processTriggerRows(rows), nil
+	} else {
+		return nil, err
+	}
+}
+
+// Executes the query to retrieve the event triggers and converts it to a list of `model.EventTrigger`
+func getEventTriggersList(queryStatement string, db *sql.DB) ([]model.EventTrigger, error) {
+	rows, err := db.Query(queryStatement)
+	if err == nil {
+		return processEventTriggerRows(rows), nil
+	} else {
+		return nil, err
+	}
+}
+
+// Executes the query to retrieve the sequences and converts it to a list of `model.Sequence`
+func getSequencesList(queryStatement string, db *sql.DB) ([]model.Sequence, error) {
+	rows, err := db.Query(queryStatement)
 	if err == nil {
-		for _, c := range columns {
-			// The entity should exists already. If not, maybe an error could be thrown
-			entity, entityExists := dataMap[c.SchemaName][c.EntityName]
-			if entityExists {
-				entity.Columns = append(entity.Columns, c)
-				dataMap[c.SchemaName][c.EntityName] = entity
-			}
-		}
+		return processSequenceRows(rows), nil
+	} else {
+		return nil, err
 	}
 }
 
-func populateRelations(dataMap map[string]map[string]model.Entity, queryStatement string, db *sql.DB) {
-	relations, err := getRelationsList(queryStatement, db)
+// Executes the query to retrieve the user-defined types and converts it to a list of `model.UserDefinedType`
+func getUserDefinedTypesList(queryStatement string, db *sql.DB) ([]model.UserDefinedType, error) {
+	rows, err := db.Query(queryStatement)
 	if err == nil {
-		for _, r := range relations {
-			// The entity should exists already. If not, maybe an error could be thrown
-			entity, entityExists := dataMap[r.SchemaName][r.EntityName]
-			if entityExists {
-				entity.Relations = append(entity.Relations, r)
-				dataMap[r.SchemaName][r.EntityName] = entity
-			}
-		}
+		return processUserDefinedTypeRows(rows), nil
+	} else {
+		return nil, err
 	}
 }
 
-// Executes the query to retrieve the entities and converts it to a list of `model.Entity`
-func getEntitiesList(queryStatement string, db *sql.DB) ([]model.Entity, error) {
+// Executes the query to retrieve the privileges and converts it to a list of `model.Grant`
+func getGrantsList(queryStatement string, db *sql.DB) ([]model.Grant, error) {
 	rows, err := db.Query(queryStatement)
 	if err == nil {
-		return processEntityRows(rows), nil
+		return processGrantRows(rows), nil
 	} else {
 		return nil, err
 	}
 }
 
-// Executes the query to retrieve the columns and converts it to a list of `model.Column`
-func getColumnsList(queryStatement string, db *sql.DB) ([]model.Column, error) {
+// Executes the query to retrieve the row-level security policies and converts it to a list of `model.Policy`
+func getPoliciesList(queryStatement string, db *sql.DB) ([]model.Policy, error) {
 	rows, err := db.Query(queryStatement)
 	if err == nil {
-		return processColumnRows(rows), nil
+		return processPolicyRows(rows), nil
 	} else {
 		return nil, err
 	}
 }
 
-func getRelationsList(queryStatement string, db *sql.DB) ([]model.Relation, error) {
+// Executes the query to retrieve a sample of rows with `columnCount` columns and converts it to a list of rows with the
+// values as text (nil for nulls). It runs concurrently when profiling, so errors reading the rows are returned instead
+// of stopping the program
+func getSampleRowsList(queryStatement string, columnCount int, db *sql.DB) ([][]*string, error) {
+	rows, err := db.Query(queryStatement)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return processSampleRows(rows, columnCount)
+}
+
+// Executes the query to retrieve the frequencies of the values of a column and converts it to a list of
+// `model.ValueFrequency`
+func getValueFrequenciesList(queryStatement string, db *sql.DB) ([]model.ValueFrequency, error) {
 	rows, err := db.Query(queryStatement)
 	if err == nil {
-		return processRelationsRows(rows), nil
+		return processValueFrequencyRows(rows), nil
 	} else {
 		return nil, err
 	}
@@ -130,6 +1113,58 @@ func validateConnection(db *sql.DB, err error) {
 	}
 }
 
+// Converts the rows that contain the results of querying the installed extensions into a list of `model.Extension`
+func processExtensionRows(rows *sql.Rows) []model.Extension {
+	extensions := make([]model.Extension, 0)
+	for rows.Next() {
+		var extension_name string
+		var version string
+		var extension_schema string
+		var comment string
+
+		err := rows.Scan(&extension_name, &version, &extension_schema, &comment)
+		if err != nil {
+			panic(err)
+		}
+
+		var extension model.Extension = model.Extension{
+			Name:       extension_name,
+			Version:    version,
+			SchemaName: strings.ToLower(extension_schema),
+			Comment:    comment}
+
+		extensions = append(extensions, extension)
+	}
+
+	return extensions
+}
+
+// Converts the rows that contain the results of querying the server settings into a list of `model.Setting`
+func processSettingRows(rows *sql.Rows) []model.Setting {
+	settings := make([]model.Setting, 0)
+	for rows.Next() {
+		var setting_name string
+		var value string
+		var unit string
+		var description string
+
+		err := rows.Scan(&setting_name, &value, &unit, &description)
+		if err != nil {
+			panic(err)
+		}
+
+		var setting model.Setting = model.Setting{
+			Name:        setting_name,
+			Value:       value,
+			Unit:        unit,
+			Description: description}
+
+		settings = append(settings, setting)
+	}
+
+	return settings
+}
+
 // Converts the rows that contain the results of querying the entities in the database into a list of `model.Entity`
 func processEntityRows(rows *sql.Rows) []model.Entity {
 	entities := make([]model.Entity, 0)
@@ -138,8 +1173,13 @@ func processEntityRows(rows *sql.Rows) []model.Entity {
 		var entity_name string
 		var entity_type string
 		var entity_comment string
+		var owner string
+		var is_row_security_enabled bool
+		var is_row_security_forced bool
 
-		err := rows.Scan(&entity_schema, &entity_name, &entity_type, &entity_comment)
+		err := rows.Scan(
+			&entity_schema, &entity_name, &entity_type, &entity_comment, &owner, &is_row_security_enabled,
+			&is_row_security_forced)
 		if err != nil {
 			panic(err)
 		}
@@ -150,10 +1190,15 @@ func processEntityRows(rows *sql.Rows) []model.Entity {
 		entityComment := entity_comment
 
 		var entity model.Entity = model.Entity{
-			Name:       entityName,
-			EntityType: entityType,
-			Comment:    entityComment,
-			SchemaName: schemaName}
+			Name:                 entityName,
+			EntityType:           entityType,
+			Comment:              entityComment,
+			SchemaName:           schemaName,
+			OriginalSchemaName:   entity_schema,
+			OriginalName:         entity_name,
+			Owner:                owner,
+			IsRowSecurityEnabled: is_row_security_enabled,
+			IsRowSecurityForced:  is_row_security_forced}
 
 		entities = append(entities, entity)
 	}
@@ -172,9 +1217,13 @@ func processColumnRows(rows *sql.Rows) []model.Column {
 		var column_comment sql.NullString
 		var is_primary_key sql.NullBool
 		var is_foreign_key sql.NullBool
+		var is_nullable bool
+		var type_schema string
+		var type_name string
 
 		err := rows.Scan(
-			&entity_schema, &entity_name, &column_name, &data_type, &column_comment, &is_primary_key, &is_foreign_key)
+			&entity_schema, &entity_name, &column_name, &data_type, &column_comment, &is_primary_key, &is_foreign_key,
+			&is_nullable, &type_schema, &type_name)
 		if err != nil {
 			panic(err)
 		}
@@ -199,13 +1248,17 @@ func processColumnRows(rows *sql.Rows) []model.Column {
 			isForeignKey = is_foreign_key.Bool
 		}
 		var column model.Column = model.Column{
-			SchemaName:   schemaName,
-			EntityName:   entityName,
-			Name:         columnName,
-			DataType:     dataType,
-			Comment:      columnComment,
-			IsPrimaryKey: isPrimaryKey,
-			IsForeignKey: isForeignKey}
+			SchemaName:     schemaName,
+			EntityName:     entityName,
+			Name:           columnName,
+			OriginalName:   column_name,
+			DataType:       dataType,
+			Comment:        columnComment,
+			IsPrimaryKey:   isPrimaryKey,
+			IsForeignKey:   isForeignKey,
+			IsNullable:     is_nullable,
+			TypeSchemaName: strings.ToLower(type_schema),
+			TypeName:       strings.ToLower(type_name)}
 
 		columns = append(columns, column)
 	}
@@ -213,6 +1266,8 @@ func processColumnRows(rows *sql.Rows) []model.Column {
 	return columns
 }
 
+// Converts the rows that contain the results of querying the relations into a list of `model.Relation`. Rows are
+// expected to come ordered, so consecutive rows of the same constraint are the pairs of columns of a single relation
 func processRelationsRows(rows *sql.Rows) []model.Relation {
 	relations := make([]model.Relation, 0)
 	for rows.Next() {
@@ -223,6 +1278,12 @@ func processRelationsRows(rows *sql.Rows) []model.Relation {
 		var foreign_table_schema string
 		var foreign_table_name string
 		var foreign_column_name string
+		var position int
+		var on_delete string
+		var on_update string
+		var match_type string
+		var is_deferrable bool
+		var is_initially_deferred bool
 
 		err := rows.Scan(
 			&entity_schema,
@@ -231,7 +1292,13 @@ func processRelationsRows(rows *sql.Rows) []model.Relation {
 			&column_name,
 			&foreign_table_schema,
 			&foreign_table_name,
-			&foreign_column_name)
+			&foreign_column_name,
+			&position,
+			&on_delete,
+			&on_update,
+			&match_type,
+			&is_deferrable,
+			&is_initially_deferred)
 		if err != nil {
 			panic(err)
 		}
@@ -244,14 +1311,28 @@ func processRelationsRows(rows *sql.Rows) []model.Relation {
 		foreignEntityname := foreign_table_name
 		foreignColumnName := foreign_column_name
 
+		columnPair := model.ColumnPair{ColumnName: columnName, ForeignColumnName: foreignColumnName}
+
+		last := len(relations) - 1
+		if last >= 0 && relations[last].SchemaName == schemaName && relations[last].EntityName == entityName &&
+			relations[last].RelationName == constraintName {
+			relations[last].ColumnPairs = append(relations[last].ColumnPairs, columnPair)
+			continue
+		}
+
 		var relation model.Relation = model.Relation{
 			SchemaName:          schemaName,
 			EntityName:          entityName,
 			RelationName:        constraintName,
-			ColumnName:          columnName,
+			ColumnPairs:         []model.ColumnPair{columnPair},
 			ForeignEntitySchema: foreignEntitySchema,
 			ForeignEntityName:   foreignEntityname,
-			ForeignColumnName:   foreignColumnName}
+			OnDelete:            on_delete,
+			OnUpdate:            on_update,
+			MatchType:           match_type,
+			IsDeferrable:        is_deferrable,
+			IsInitiallyDeferred: is_initially_deferred,
+			Confidence:          1}
 
 		relations = append(relations, relation)
 	}
@@ -259,7 +1340,572 @@ func processRelationsRows(rows *sql.Rows) []model.Relation {
 	return relations
 }
 
-func buildSchemeList(dataMap map[string]map[string]model.Entity) []model.Schema {
+// Converts the rows that contain the results of querying the check constraints into a list of `model.CheckConstraint`
+func processCheckConstraintRows(rows *sql.Rows) []model.CheckConstraint {
+	checkConstraints := make([]model.CheckConstraint, 0)
+	for rows.Next() {
+		var entity_schema string
+		var entity_name string
+		var constraint_name string
+		var expression string
+		var column_names string
+
+		err := rows.Scan(&entity_schema, &entity_name, &constraint_name, &expression, &column_names)
+		if err != nil {
+			panic(err)
+		}
+
+		var checkConstraint model.CheckConstraint = model.CheckConstraint{
+			SchemaName:  strings.ToLower(entity_schema),
+			EntityName:  strings.ToLower(entity_name),
+			Name:        strings.ToLower(constraint_name),
+			Expression:  expression,
+			ColumnNames: splitNameList(column_names)}
+
+		checkConstraints = append(checkConstraints, checkConstraint)
+	}
+
+	return checkConstraints
+}
+
+// Converts the rows that contain the results of querying the unique keys into a list of `model.UniqueKey`
+func processUniqueKeyRows(rows *sql.Rows) []model.UniqueKey {
+	uniqueKeys := make([]model.UniqueKey, 0)
+	for rows.Next() {
+		var entity_schema string
+		var entity_name string
+		var key_name string
+		var column_names string
+		var is_primary_key bool
+
+		err := rows.Scan(&entity_schema, &entity_name, &key_name, &column_names, &is_primary_key)
+		if err != nil {
+			panic(err)
+		}
+
+		var uniqueKey model.UniqueKey = model.UniqueKey{
+			SchemaName:   strings.ToLower(entity_schema),
+			EntityName:   strings.ToLower(entity_name),
+			Name:         strings.ToLower(key_name),
+			ColumnNames:  splitNameList(column_names),
+			IsPrimaryKey: is_primary_key}
+
+		uniqueKeys = append(uniqueKeys, uniqueKey)
+	}
+
+	return uniqueKeys
+}
+
+// Converts the rows that contain the results of querying the view dependencies into a list of `viewDependency`
+func processViewDependencyRows(rows *sql.Rows) []viewDependency {
+	dependencies := make([]viewDependency, 0)
+	for rows.Next() {
+		var view_schema string
+		var view_name string
+		var view_type string
+		var table_schema string
+		var table_name string
+		var table_type string
+
+		err := rows.Scan(&view_schema, &view_name, &view_type, &table_schema, &table_name, &table_type)
+		if err != nil {
+			panic(err)
+		}
+
+		dependency := viewDependency{
+			view: model.EntityReference{
+				SchemaName: strings.ToLower(view_schema),
+				EntityName: strings.ToLower(view_name),
+				EntityType: processType(view_type)},
+			table: model.EntityReference{
+				SchemaName: strings.ToLower(table_schema),
+				EntityName: strings.ToLower(table_name),
+				EntityType: processType(table_type)}}
+
+		dependencies = append(dependencies, dependency)
+	}
+
+	return dependencies
+}
+
+// Converts the rows that contain the results of querying the columns read by views into a list of `viewColumnUsage`
+func processViewColumnUsageRows(rows *sql.Rows) []viewColumnUsage {
+	usages := make([]viewColumnUsage, 0)
+	for rows.Next() {
+		var view_schema string
+		var view_name string
+		var table_schema string
+		var table_name string
+		var column_name string
+
+		err := rows.Scan(&view_schema, &view_name, &table_schema, &table_name, &column_name)
+		if err != nil {
+			panic(err)
+		}
+
+		usage := viewColumnUsage{
+			viewSchema: strings.ToLower(view_schema),
+			viewName:   strings.ToLower(view_name),
+			source: model.ColumnLineage{
+				SourceSchemaName: strings.ToLower(table_schema),
+				SourceEntityName: strings.ToLower(table_name),
+				SourceColumnName: strings.ToLower(column_name)}}
+
+		usages = append(usages, usage)
+	}
+
+	return usages
+}
+
+// Converts the rows that contain the results of querying the inheritance relationships into a list of `inheritance`
+func processInheritanceRows(rows *sql.Rows) []inheritance {
+	inheritances := make([]inheritance, 0)
+	for rows.Next() {
+		var child_schema string
+		var child_name string
+		var child_type string
+		var parent_schema string
+		var parent_name string
+		var parent_type string
+		var partition_bound string
+
+		err := rows.Scan(
+			&child_schema, &child_name, &child_type, &parent_schema, &parent_name, &parent_type, &partition_bound)
+		if err != nil {
+			panic(err)
+		}
+
+		inheritance := inheritance{
+			child: model.EntityReference{
+				SchemaName: strings.ToLower(child_schema),
+				EntityName: strings.ToLower(child_name),
+				EntityType: processType(child_type)},
+			parent: model.EntityReference{
+				SchemaName: strings.ToLower(parent_schema),
+				EntityName: strings.ToLower(parent_name),
+				EntityType: processType(parent_type)},
+			partitionBound: partition_bound}
+
+		inheritances = append(inheritances, inheritance)
+	}
+
+	return inheritances
+}
+
+// Converts the rows that contain the results of querying the routines into a list of `model.Routine`. Rows are
+// expected to come ordered, so consecutive rows of the same routine are its arguments
+func processRoutineRows(rows *sql.Rows) []model.Routine {
+	routines := make([]model.Routine, 0)
+	for rows.Next() {
+		var routine_schema string
+		var routine_name string
+		var routine_signature string
+		var routine_kind string
+		var return_type string
+		var language string
+		var volatility string
+		var is_security_definer bool
+		var comment string
+		var source string
+		var argument_position int
+		var argument_name string
+		var argument_type string
+		var argument_mode string
+
+		err := rows.Scan(
+			&routine_schema,
+			&routine_name,
+			&routine_signature,
+			&routine_kind,
+			&return_type,
+			&language,
+			&volatility,
+			&is_security_definer,
+			&comment,
+			&source,
+			&argument_position,
+			&argument_name,
+			&argument_type,
+			&argument_mode)
+		if err != nil {
+			panic(err)
+		}
+
+		schemaName := strings.ToLower(routine_schema)
+
+		last := len(routines) - 1
+		if last < 0 || routines[last].SchemaName != schemaName || routines[last].Signature != routine_signature {
+			var routine model.Routine = model.Routine{
+				SchemaName:        schemaName,
+				Name:              strings.ToLower(routine_name),
+				Signature:         routine_signature,
+				Kind:              routine_kind,
+				ReturnType:        return_type,
+				Language:          language,
+				Volatility:        volatility,
+				IsSecurityDefiner: is_security_definer,
+				Comment:           comment,
+				Source:            source}
+			routines = append(routines, routine)
+			last++
+		}
+
+		if argument_position == 0 {
+			continue
+		}
+		argument := model.RoutineArgument{Name: argument_name, DataType: argument_type, Mode: argument_mode}
+		if argument_mode == "TABLE" {
+			routines[last].ReturnColumns = append(routines[last].ReturnColumns, argument)
+		} else {
+			routines[last].Arguments = append(routines[last].Arguments, argument)
+		}
+	}
+
+	return routines
+}
+
+// Converts the rows that contain the results of querying the triggers into a list of `model.Trigger`
+func processTriggerRows(rows *sql.Rows) []model.Trigger {
+	triggers := make([]model.Trigger, 0)
+	for rows.Next() {
+		var table_schema string
+		var table_name string
+		var trigger_name string
+		var timing string
+		var events string
+		var level string
+		var function_schema string
+		var function_name string
+		var enabled string
+		var condition string
+		var comment string
+
+		err := rows.Scan(
+			&table_schema,
+			&table_name,
+			&trigger_name,
+			&timing,
+			&events,
+			&level,
+			&function_schema,
+			&function_name,
+			&enabled,
+			&condition,
+			&comment)
+		if err != nil {
+			panic(err)
+		}
+
+		var trigger model.Trigger = model.Trigger{
+			SchemaName:     strings.ToLower(table_schema),
+			EntityName:     strings.ToLower(table_name),
+			Name:           strings.ToLower(trigger_name),
+			Timing:         timing,
+			Events:         strings.Split(events, ","),
+			Level:          level,
+			FunctionSchema: function_schema,
+			FunctionName:   function_name,
+			Enabled:        enabled,
+			Condition:      condition,
+			Comment:        comment}
+
+		triggers = append(triggers, trigger)
+	}
+
+	return triggers
+}
+
+// Converts the rows that contain the results of querying the event triggers into a list of `model.EventTrigger`
+func processEventTriggerRows(rows *sql.Rows) []model.EventTrigger {
+	eventTriggers := make([]model.EventTrigger, 0)
+	for rows.Next() {
+		var trigger_name string
+		var event string
+		var tags string
+		var function_schema string
+		var function_name string
+		var enabled string
+		var comment string
+
+		err := rows.Scan(&trigger_name, &event, &tags, &function_schema, &function_name, &enabled, &comment)
+		if err != nil {
+			panic(err)
+		}
+
+		var eventTrigger model.EventTrigger = model.EventTrigger{
+			Name:           trigger_name,
+			Event:          event,
+			Tags:           make([]string, 0),
+			FunctionSchema: function_schema,
+			FunctionName:   function_name,
+			Enabled:        enabled,
+			Comment:        comment}
+		if tags != "" {
+			eventTrigger.Tags = strings.Split(tags, ",")
+		}
+
+		eventTriggers = append(eventTriggers, eventTrigger)
+	}
+
+	return eventTriggers
+}
+
+// Converts the rows that contain the results of querying the sequences into a list of `model.Sequence`
+func processSequenceRows(rows *sql.Rows) []model.Sequence {
+	sequences := make([]model.Sequence, 0)
+	for rows.Next() {
+		var sequence_schema string
+		var sequence_name string
+		var data_type string
+		var start_value int64
+		var current_value sql.NullInt64
+		var increment int64
+		var min_value int64
+		var max_value int64
+		var cache_size int64
+		var is_cycle bool
+		var owner_schema string
+		var owner_table string
+		var owner_column string
+		var is_identity bool
+
+		err := rows.Scan(
+			&sequence_schema,
+			&sequence_name,
+			&data_type,
+			&start_value,
+			&current_value,
+			&increment,
+			&min_value,
+			&max_value,
+			&cache_size,
+			&is_cycle,
+			&owner_schema,
+			&owner_table,
+			&owner_column,
+			&is_identity)
+		if err != nil {
+			panic(err)
+		}
+
+		var currentValue *int64
+		if current_value.Valid {
+			currentValue = &current_value.Int64
+		}
+
+		var sequence model.Sequence = model.Sequence{
+			SchemaName:      strings.ToLower(sequence_schema),
+			Name:            strings.ToLower(sequence_name),
+			DataType:        data_type,
+			StartValue:      start_value,
+			CurrentValue:    currentValue,
+			Increment:       increment,
+			MinValue:        min_value,
+			MaxValue:        max_value,
+			CacheSize:       cache_size,
+			IsCycle:         is_cycle,
+			OwnerSchemaName: strings.ToLower(owner_schema),
+			OwnerEntityName: strings.ToLower(owner_table),
+			OwnerColumnName: strings.ToLower(owner_column),
+			IsIdentity:      is_identity}
+
+		sequences = append(sequences, sequence)
+	}
+
+	return sequences
+}
+
+// Converts the rows that contain the results of querying the user-defined types into a list of `model.UserDefinedType`
+func processUserDefinedTypeRows(rows *sql.Rows) []model.UserDefinedType {
+	types := make([]model.UserDefinedType, 0)
+	for rows.Next() {
+		var type_schema string
+		var type_name string
+		var type_kind string
+		var base_type string
+		var is_not_null bool
+		var default_value string
+		var comment string
+
+		err := rows.Scan(&type_schema, &type_name, &type_kind, &base_type, &is_not_null, &default_value, &comment)
+		if err != nil {
+			panic(err)
+		}
+
+		var userDefinedType model.UserDefinedType = model.UserDefinedType{
+			SchemaName: strings.ToLower(type_schema),
+			Name:       strings.ToLower(type_name),
+			Kind:       type_kind,
+			Comment:    comment,
+			BaseType:   base_type,
+			IsNotNull:  is_not_null,
+			Default:    default_value}
+
+		types = append(types, userDefinedType)
+	}
+
+	return types
+}
+
+// Converts the rows that contain the results of querying the privileges into a list of `model.Grant`
+func processGrantRows(rows *sql.Rows) []model.Grant {
+	grants := make([]model.Grant, 0)
+	for rows.Next() {
+		var object_schema string
+		var object_name string
+		var column_name string
+		var grantee string
+		var grantor string
+		var privilege string
+		var is_grantable bool
+
+		err := rows.Scan(&object_schema, &object_name, &column_name, &grantee, &grantor, &privilege, &is_grantable)
+		if err != nil {
+			panic(err)
+		}
+
+		var grant model.Grant = model.Grant{
+			SchemaName:  strings.ToLower(object_schema),
+			EntityName:  strings.ToLower(object_name),
+			ColumnName:  strings.ToLower(column_name),
+			Grantee:     grantee,
+			Grantor:     grantor,
+			Privilege:   privilege,
+			IsGrantable: is_grantable}
+
+		grants = append(grants, grant)
+	}
+
+	return grants
+}
+
+// Converts the rows that contain the results of querying the row-level security policies into a list of
+// `model.Policy`
+func processPolicyRows(rows *sql.Rows) []model.Policy {
+	policies := make([]model.Policy, 0)
+	for rows.Next() {
+		var table_schema string
+		var table_name string
+		var policy_name string
+		var command string
+		var roles string
+		var is_permissive bool
+		var using_expression string
+		var with_check_expression string
+
+		err := rows.Scan(
+			&table_schema,
+			&table_name,
+			&policy_name,
+			&command,
+			&roles,
+			&is_permissive,
+			&using_expression,
+			&with_check_expression)
+		if err != nil {
+			panic(err)
+		}
+
+		var policy model.Policy = model.Policy{
+			SchemaName:   strings.ToLower(table_schema),
+			EntityName:   strings.ToLower(table_name),
+			Name:         policy_name,
+			Command:      command,
+			Roles:        strings.Split(roles, ","),
+			IsPermissive: is_permissive,
+			Using:        using_expression,
+			WithCheck:    with_check_expression}
+
+		policies = append(policies, policy)
+	}
+
+	return policies
+}
+
+// Converts the rows that contain the results of querying a sample of rows into a list of rows with the values as text.
+// Nulls are nil
+func processSampleRows(rows *sql.Rows, columnCount int) ([][]*string, error) {
+	sampleRows := make([][]*string, 0)
+	for rows.Next() {
+		values := make([]sql.NullString, columnCount)
+		pointers := make([]any, columnCount)
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		row := make([]*string, columnCount)
+		for i, v := range values {
+			if v.Valid {
+				value := v.String
+				row[i] = &value
+			}
+		}
+		sampleRows = append(sampleRows, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return sampleRows, nil
+}
+
+// Converts the rows that contain the results of querying the frequencies of the values of a column into a list of
+// `model.ValueFrequency`
+func processValueFrequencyRows(rows *sql.Rows) []model.ValueFrequency {
+	values := make([]model.ValueFrequency, 0)
+	for rows.Next() {
+		var value string
+		var row_count int64
+		var total_count int64
+
+		err := rows.Scan(&value, &row_count, &total_count)
+		if err != nil {
+			panic(err)
+		}
+
+		var valueFrequency model.ValueFrequency = model.ValueFrequency{
+			Value:     value,
+			Count:     row_count,
+			Frequency: float64(row_count) / float64(total_count)}
+
+		values = append(values, valueFrequency)
+	}
+
+	return values
+}
+
+// Returns a pointer to the time in `value`, or nil if it is null
+func getTimePointer(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
+
+// Splits a comma separated list of names into a list of lower case names
+func splitNameList(names string) []string {
+	list := make([]string, 0)
+	for _, name := range strings.Split(names, ",") {
+		if name != "" {
+			list = append(list, strings.ToLower(name))
+		}
+	}
+	return list
+}
+
+// Builds the list of schemas with the entities in `dataMap` and the rest of objects in `schemaMap`
+func buildSchemeList(dataMap map[string]map[string]model.Entity, schemaMap map[string]model.Schema) []model.Schema {
+	// Schemas without entities can still have other objects
+	for schemaKey := range schemaMap {
+		if _, schemaExists := dataMap[schemaKey]; !schemaExists {
+			dataMap[schemaKey] = make(map[string]model.Entity)
+		}
+	}
+
 	var schemas = make([]model.Schema, 0)
 	// Populate the list of schemas
 	for schemaKey, entityMap := range dataMap {
@@ -267,18 +1913,30 @@ func buildSchemeList(dataMap map[string]map[string]model.Entity) []model.Schema
 		for _, value := range entityMap {
 			entities = append(entities, value)
 		}
-		schema := model.Schema{Name: schemaKey, Entities: entities}
+		schema := schemaMap[schemaKey]
+		schema.Name = schemaKey
+		schema.Entities = entities
 		schemas = append(schemas, schema)
 	}
 	return schemas
 }
 
-func processType(originalType string) string {
+// Converts the type of an entity as returned by the database into a [model.EntityType]
+func processType(originalType string) model.EntityType {
 	entityType := strings.ToLower(originalType)
-	if strings.Contains(entityType, "view") {
-		entityType = "view"
-	} else if strings.Contains(entityType, "table") {
-		entityType = "table"
+	switch {
+	case strings.Contains(entityType, "materialized"):
+		return model.MaterializedView
+	case strings.Contains(entityType, "view"):
+		return model.View
+	case strings.Contains(entityType, "foreign"):
+		return model.ForeignTable
+	case strings.Contains(entityType, "partitioned"):
+		return model.PartitionedTable
+	case strings.Contains(entityType, "partition"):
+		return model.Partition
+	case strings.Contains(entityType, "table"):
+		return model.Table
 	}
-	return entityType
+	return model.EntityType(entityType)
 }
@@ -0,0 +1,177 @@
+package extractor
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/PDCMFinder/db-descriptor/pkg/connector"
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
+)
+
+// Version of JSON Schema the inferred schemas follow
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Objects with more distinct keys than this are considered maps, whose keys are data instead of properties
+const maxJsonSchemaProperties = 100
+
+// Order in which the types are listed in a schema
+var jsonSchemaTypes = []string{"object", "array", "string", "number", "integer", "boolean", "null"}
+
+// Accumulates what is observed in a set of JSON values to build their schema
+type jsonSchemaBuilder struct {
+	count      int64
+	typeCounts map[string]int64
+	properties map[string]*jsonSchemaBuilder
+	items      *jsonSchemaBuilder
+}
+
+func newJsonSchemaBuilder() *jsonSchemaBuilder {
+	return &jsonSchemaBuilder{typeCounts: make(map[string]int64), properties: make(map[string]*jsonSchemaBuilder)}
+}
+
+/*
+Populates the json and jsonb columns of the tables, partitioned tables and materialized views in `dataMap` with the
+schema of their documents, inferred from the values of the column in the first `input.JsonSchemaSamples` rows. Nulls
+are ignored.
+*/
+func populateJsonSchemas(
+	dataMap map[string]map[string]model.Entity, dBConnector connector.DBConnector, input connector.Input, db *sql.DB) {
+	for _, e := range getEntitiesWithStorage(dataMap, false) {
+		entity := dataMap[e.SchemaName][e.EntityName]
+		for i := range entity.Columns {
+			column := &entity.Columns[i]
+			dataType := strings.ToLower(column.DataType)
+			if dataType != "json" && dataType != "jsonb" {
+				continue
+			}
+
+			schemaName, entityName := getEntityQueryNames(entity)
+			queryStatement := dBConnector.GetSampleRowsQueryStatement(
+				schemaName, entityName, getColumnQueryNames(entity, []string{column.Name}), 0, input.JsonSchemaSamples)
+			rows, err := getSampleRowsList(queryStatement, 1, db)
+			if err != nil {
+				log.Printf("Could not sample the values of %s.%s.%s. Error: %s", entity.SchemaName, entity.Name,
+					column.Name, err.Error())
+				continue
+			}
+
+			builder := newJsonSchemaBuilder()
+			for _, row := range rows {
+				if row[0] == nil {
+					continue
+				}
+				decoder := json.NewDecoder(strings.NewReader(*row[0]))
+				// Numbers are kept as text to distinguish integers
+				decoder.UseNumber()
+				var document any
+				if decoder.Decode(&document) == nil {
+					builder.add(document)
+				}
+			}
+			if builder.count > 0 {
+				schema := builder.build()
+				schema.Schema = jsonSchemaDialect
+				column.JsonSchema = schema
+			}
+		}
+		dataMap[e.SchemaName][e.EntityName] = entity
+	}
+}
+
+// Adds a decoded JSON value to what is observed
+func (b *jsonSchemaBuilder) add(value any) {
+	b.count++
+	switch v := value.(type) {
+	case map[string]any:
+		b.typeCounts["object"]++
+		for key, propertyValue := range v {
+			property, propertyExists := b.properties[key]
+			if !propertyExists {
+				property = newJsonSchemaBuilder()
+				b.properties[key] = property
+			}
+			property.add(propertyValue)
+		}
+	case []any:
+		b.typeCounts["array"]++
+		if b.items == nil {
+			b.items = newJsonSchemaBuilder()
+		}
+		for _, item := range v {
+			b.items.add(item)
+		}
+	case string:
+		b.typeCounts["string"]++
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			b.typeCounts["number"]++
+		} else {
+			b.typeCounts["integer"]++
+		}
+	case bool:
+		b.typeCounts["boolean"]++
+	case nil:
+		b.typeCounts["null"]++
+	}
+}
+
+// Adds everything observed by another builder to what is observed by this one
+func (b *jsonSchemaBuilder) merge(other *jsonSchemaBuilder) {
+	b.count += other.count
+	for t, count := range other.typeCounts {
+		b.typeCounts[t] += count
+	}
+	for key, otherProperty := range other.properties {
+		property, propertyExists := b.properties[key]
+		if !propertyExists {
+			property = newJsonSchemaBuilder()
+			b.properties[key] = property
+		}
+		property.merge(otherProperty)
+	}
+	if other.items != nil {
+		if b.items == nil {
+			b.items = newJsonSchemaBuilder()
+		}
+		b.items.merge(other.items)
+	}
+}
+
+// Builds the schema of the observed values
+func (b *jsonSchemaBuilder) build() *model.JsonSchema {
+	schema := &model.JsonSchema{Count: b.count}
+	for _, t := range jsonSchemaTypes {
+		// Integers are numbers, so they are not listed when other numbers were seen
+		if b.typeCounts[t] > 0 && !(t == "integer" && b.typeCounts["number"] > 0) {
+			schema.Type = append(schema.Type, t)
+		}
+	}
+
+	objectCount := b.typeCounts["object"]
+	if len(b.properties) > maxJsonSchemaProperties {
+		additional := newJsonSchemaBuilder()
+		for _, property := range b.properties {
+			additional.merge(property)
+		}
+		schema.AdditionalProperties = additional.build()
+	} else if len(b.properties) > 0 {
+		schema.Properties = make(map[string]*model.JsonSchema)
+		for key, property := range b.properties {
+			propertySchema := property.build()
+			propertySchema.Frequency = float64(property.count) / float64(objectCount)
+			schema.Properties[key] = propertySchema
+			if property.count == objectCount {
+				schema.Required = append(schema.Required, key)
+			}
+		}
+		sort.Strings(schema.Required)
+	}
+
+	if b.items != nil && b.items.count > 0 {
+		schema.Items = b.items.build()
+	}
+	return schema
+}
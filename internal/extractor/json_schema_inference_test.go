@@ -0,0 +1,118 @@
+package extractor
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
+)
+
+// Decodes JSON documents like the values of a column
+func decodeJsonDocuments(t *testing.T, documents []string) []any {
+	values := make([]any, 0, len(documents))
+	for _, document := range documents {
+		decoder := json.NewDecoder(strings.NewReader(document))
+		decoder.UseNumber()
+		var value any
+		if err := decoder.Decode(&value); err != nil {
+			t.Fatalf("could not decode %q: %s", document, err)
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+func TestJsonSchemaBuilder(t *testing.T) {
+	tests := []struct {
+		name      string
+		documents []string
+		expected  *model.JsonSchema
+	}{
+		{
+			name:      "scalars",
+			documents: []string{`"a"`, `1`, `null`},
+			expected:  &model.JsonSchema{Type: []string{"string", "integer", "null"}, Count: 3},
+		},
+		{
+			name:      "integers are numbers",
+			documents: []string{`1`, `1.5`, `2e3`},
+			expected:  &model.JsonSchema{Type: []string{"number"}, Count: 3},
+		},
+		{
+			name:      "required and optional properties",
+			documents: []string{`{"id": 1, "tag": "x"}`, `{"id": 2}`},
+			expected: &model.JsonSchema{
+				Type: []string{"object"},
+				Properties: map[string]*model.JsonSchema{
+					"id":  {Type: []string{"integer"}, Count: 2, Frequency: 1},
+					"tag": {Type: []string{"string"}, Count: 1, Frequency: 0.5},
+				},
+				Required: []string{"id"},
+				Count:    2,
+			},
+		},
+		{
+			name:      "arrays",
+			documents: []string{`[1, 2]`, `["a"]`, `[]`},
+			expected: &model.JsonSchema{
+				Type:  []string{"array"},
+				Items: &model.JsonSchema{Type: []string{"string", "integer"}, Count: 3},
+				Count: 3,
+			},
+		},
+		{
+			name:      "empty arrays",
+			documents: []string{`[]`},
+			expected:  &model.JsonSchema{Type: []string{"array"}, Count: 1},
+		},
+		{
+			name:      "objects and other types",
+			documents: []string{`{"a": true}`, `false`},
+			expected: &model.JsonSchema{
+				Type: []string{"object", "boolean"},
+				Properties: map[string]*model.JsonSchema{
+					"a": {Type: []string{"boolean"}, Count: 1, Frequency: 1},
+				},
+				Required: []string{"a"},
+				Count:    2,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			builder := newJsonSchemaBuilder()
+			for _, value := range decodeJsonDocuments(t, test.documents) {
+				builder.add(value)
+			}
+			schema := builder.build()
+			if !reflect.DeepEqual(schema, test.expected) {
+				actual, _ := json.Marshal(schema)
+				expected, _ := json.Marshal(test.expected)
+				t.Errorf("build() = %s, expected %s", actual, expected)
+			}
+		})
+	}
+}
+
+func TestJsonSchemaBuilderWithManyKeys(t *testing.T) {
+	// Objects with more keys than properties allowed are maps, described by their values
+	document := make(map[string]any)
+	for i := 0; i <= maxJsonSchemaProperties; i++ {
+		document[fmt.Sprintf("key%d", i)] = json.Number(fmt.Sprint(i))
+	}
+	builder := newJsonSchemaBuilder()
+	builder.add(document)
+	schema := builder.build()
+
+	expected := &model.JsonSchema{
+		Type:                 []string{"object"},
+		AdditionalProperties: &model.JsonSchema{Type: []string{"integer"}, Count: maxJsonSchemaProperties + 1},
+		Count:                1,
+	}
+	if !reflect.DeepEqual(schema, expected) {
+		t.Errorf("build() = %+v, expected %+v", schema, expected)
+	}
+}
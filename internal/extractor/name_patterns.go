@@ -0,0 +1,48 @@
+package extractor
+
+import (
+	"path"
+	"strings"
+)
+
+/*
+Returns true if a column matches any of the patterns in `patterns`.
+
+Patterns are glob patterns (`*`, `?`, `[...]`) matched against the name of the column, the entity name and the column
+name separated by a dot, or the schema, entity and column names separated by dots, depending on the number of dots
+in the pattern. For example, `*_id`, `patient.*` and `public.patient.sex`. Matching is case insensitive.
+*/
+func matchesColumnPattern(patterns []string, schemaName string, entityName string, columnName string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		var name string
+		switch strings.Count(pattern, ".") {
+		case 0:
+			name = columnName
+		case 1:
+			name = entityName + "." + columnName
+		default:
+			name = schemaName + "." + entityName + "." + columnName
+		}
+		if matched, err := path.Match(pattern, strings.ToLower(name)); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if an entity matches any of the patterns in `patterns`. Patterns are glob patterns matched against the
+// entity name or, if they contain a dot, the schema and entity names separated by a dot. Matching is case insensitive.
+func matchesEntityPattern(patterns []string, schemaName string, entityName string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		name := entityName
+		if strings.Contains(pattern, ".") {
+			name = schemaName + "." + entityName
+		}
+		if matched, err := path.Match(pattern, strings.ToLower(name)); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
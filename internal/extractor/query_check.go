@@ -0,0 +1,864 @@
+package extractor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
+)
+
+// Name of the prepared statement used to check queries against a database
+const checkStatementName = "db_descriptor_check"
+
+// Words that start the statements that are checked. Other statements, like DDL, are skipped
+var checkableStatementKeywords = []string{"SELECT", "WITH", "VALUES", "TABLE", "INSERT", "UPDATE", "DELETE"}
+
+// Words that are not column references in the expressions of a select: arguments of functions, like in
+// extract(year from x), and the words of the clauses that follow the FROM clause
+var checkerKeywords = []string{
+	"YEAR", "MONTH", "DAY", "HOUR", "MINUTE", "SECOND", "EPOCH", "DOW", "DOY", "WEEK", "QUARTER", "DECADE", "CENTURY",
+	"MILLENNIUM", "MILLISECONDS", "MICROSECONDS", "TIMEZONE", "BOTH", "LEADING", "TRAILING", "FOR", "AT", "TIME", "ZONE",
+	"WITH", "WITHOUT", "VARYING", "PRECISION", "DEFAULT", "ONLY", "LIMIT", "OFFSET", "FETCH", "NEXT", "TIES", "HAVING",
+	"WINDOW", "OF", "UPDATE", "SHARE", "NOWAIT", "SKIP", "LOCKED", "NO", "KEY", "USING", "RETURNING", "VALUES",
+}
+
+// Words that can come before the left operand of a comparison
+var comparisonPrefixKeywords = []string{"AND", "OR", "NOT", "WHERE", "ON", "WHEN", "HAVING", "THEN", "ELSE"}
+
+// Messages of the problems found in the text of a statement, before its entities and columns are checked
+const (
+	unterminatedTokenMessage     = "unterminated quoted string or comment"
+	unbalancedParenthesisMessage = "unbalanced parenthesis"
+)
+
+// Operators whose operands must have comparable types
+var comparisonOperators = []string{"=", "<>", "!=", "<", ">", "<=", ">="}
+
+// Schemas of the system catalogs, which are not in descriptions
+var catalogSchemas = []string{"pg_catalog", "information_schema"}
+
+// A statement of a SQL file and the line where it starts
+type sqlStatement struct {
+	fileName string
+	text     string
+	line     int
+}
+
+// An entity, subquery or common table expression read by a query, with the name it is referenced by. `entity` is nil
+// when its columns are not known
+type queryScopeItem struct {
+	name   string
+	entity *model.Entity
+}
+
+// The entities a part of a query can reference: the items of its FROM clause, the names of the columns of its result
+// and, for subqueries, the entities of the queries that contain them
+type queryScope struct {
+	items       []queryScopeItem
+	outputNames []string
+	outer       *queryScope
+}
+
+// A problem found by the static checks, at an offset (in runes) of the statement
+type queryProblem struct {
+	position int
+	message  string
+}
+
+// Checks SQL statements against the entities of a description without a database
+type staticQueryChecker struct {
+	dataMap  map[string]map[string]model.Entity
+	problems []queryProblem
+}
+
+/*
+Checks the statements in the SQL files of `input.QueryFiles` (or in the .sql files of its directories) against the
+database.
+
+Each statement is prepared, but not executed, in a read-only transaction, so the database verifies that the entities
+and columns it references exist and that their types are compatible. The position of each error is mapped to the line
+of the file. Statements other than queries and data changes (like DDL) are skipped.
+*/
+func (d dbDescriptionExtractor) CheckQueries() []model.QueryIssue {
+	db, err := d.dBConnector.GetConnection()
+	validateConnection(db, err)
+	defer db.Close()
+
+	issues := make([]model.QueryIssue, 0)
+	for _, statement := range readSqlStatements(d.input.QueryFiles) {
+		tokens, ok := tokenizeSQL(statement.text)
+		if !ok {
+			issues = append(issues, model.QueryIssue{
+				FileName: statement.fileName, Line: statement.line, Message: unterminatedTokenMessage})
+			continue
+		}
+		if !isCheckableStatement(tokens) {
+			continue
+		}
+		if message, position, failed := d.prepareStatement(statement.text, db); failed {
+			issues = append(issues, model.QueryIssue{
+				FileName: statement.fileName, Line: statement.lineAt(position), Message: message})
+		}
+	}
+	return issues
+}
+
+// Prepares a statement in a read-only transaction that is rolled back (see prepareQuery). If the statement is not
+// valid, returns the error message, the position (in runes) of the error in the statement or -1 if it is not known,
+// and true
+func (d dbDescriptionExtractor) prepareStatement(text string, db *sql.DB) (string, int, bool) {
+	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		message, _ := d.dBConnector.GetErrorDetails(err)
+		return message, -1, true
+	}
+	defer tx.Rollback()
+
+	if err := prepareQuery(tx, d.dBConnector, checkStatementName, text); err != nil {
+		message, position := d.dBConnector.GetErrorDetails(err)
+		if position <= 0 {
+			return message, -1, true
+		}
+		prepareStatement := d.dBConnector.GetPrepareQueryStatement(checkStatementName, text)
+		queryStart := utf8.RuneCountInString(prepareStatement[:strings.Index(prepareStatement, text)])
+		return message, position - 1 - queryStart, true
+	}
+	return "", 0, false
+}
+
+/*
+Checks the statements in the SQL files `fileNames` (or in the .sql files of directories) against the entities of a
+description of the database, without connecting to it.
+
+Selects, including their subqueries and common table expressions, are checked for entities and columns that do not
+exist and for comparisons between columns, or columns and literals, of incompatible types. Inserts, updates and deletes
+are checked for their target entity and the columns they set. Entities of the system catalogs and of schemas that are
+not in the description are not known, so the columns read from them are not checked.
+*/
+func CheckQueriesAgainstDescription(description model.DatabaseDescription, fileNames []string) []model.QueryIssue {
+	dataMap := make(map[string]map[string]model.Entity)
+	var addEntity func(entity model.Entity)
+	addEntity = func(entity model.Entity) {
+		if _, schemaExists := dataMap[entity.SchemaName]; !schemaExists {
+			dataMap[entity.SchemaName] = make(map[string]model.Entity)
+		}
+		dataMap[entity.SchemaName][entity.Name] = entity
+		for _, partition := range entity.Partitions {
+			addEntity(partition)
+		}
+	}
+	for _, schema := range description.Schemas {
+		for _, entity := range schema.Entities {
+			addEntity(entity)
+		}
+	}
+
+	issues := make([]model.QueryIssue, 0)
+	for _, statement := range readSqlStatements(fileNames) {
+		checker := staticQueryChecker{dataMap: dataMap}
+		tokens, ok := tokenizeSQL(statement.text)
+		switch {
+		case !ok:
+			checker.addProblem(-1, unterminatedTokenMessage)
+		case !isCheckableStatement(tokens):
+			continue
+		default:
+			if position := findUnbalancedParenthesis(tokens); position >= 0 {
+				// The clauses of the statement cannot be told apart
+				checker.addProblem(position, unbalancedParenthesisMessage)
+			} else {
+				checker.checkStatement(tokens, nil, make(map[string]bool))
+			}
+		}
+		for _, p := range checker.problems {
+			issues = append(issues, model.QueryIssue{
+				FileName: statement.fileName, Line: statement.lineAt(p.position), Message: p.message})
+		}
+	}
+	sortQueryIssues(issues)
+	return issues
+}
+
+// Reads the statements of SQL files, or of the .sql files of directories. Files that cannot be read stop the program
+func readSqlStatements(fileNames []string) []sqlStatement {
+	statements := make([]sqlStatement, 0)
+	for _, fileName := range expandSqlFileNames(fileNames) {
+		content, err := os.ReadFile(fileName)
+		if err != nil {
+			log.Fatal("Could not read the query file. Error: ", err)
+		}
+		statements = append(statements, splitSqlStatements(fileName, string(content))...)
+	}
+	return statements
+}
+
+// Splits the text of a SQL file into its statements, separated by semicolons. Comments before a statement are not
+// part of it. If the text cannot be tokenized, it is returned as a single statement
+func splitSqlStatements(fileName string, text string) []sqlStatement {
+	runes := []rune(text)
+	lineAt := func(position int) int {
+		return strings.Count(string(runes[:position]), "\n") + 1
+	}
+	tokens, ok := tokenizeSQL(text)
+	if !ok {
+		return []sqlStatement{{fileName: fileName, text: text, line: 1}}
+	}
+
+	statements := make([]sqlStatement, 0)
+	start := -1
+	for i, t := range tokens {
+		if start < 0 && !isSymbol(t, ";") {
+			start = i
+		}
+		if start >= 0 && (isSymbol(t, ";") || i == len(tokens)-1) {
+			end := i
+			if !isSymbol(t, ";") {
+				end = i + 1
+			}
+			statements = append(statements, sqlStatement{
+				fileName: fileName,
+				text:     string(runes[tokens[start].start:tokens[end-1].end]),
+				line:     lineAt(tokens[start].start)})
+			start = -1
+		}
+	}
+	return statements
+}
+
+// Returns the line of the file at an offset (in runes) of the statement, or the line where the statement starts if
+// the offset is negative
+func (s sqlStatement) lineAt(position int) int {
+	if position < 0 {
+		return s.line
+	}
+	runes := []rune(s.text)
+	if position > len(runes) {
+		position = len(runes)
+	}
+	return s.line + strings.Count(string(runes[:position]), "\n")
+}
+
+func isCheckableStatement(tokens []sqlToken) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	first := tokens[0]
+	if isSymbol(first, "(") {
+		return true
+	}
+	return first.kind == identifierToken && !first.quoted && containsKeyword(checkableStatementKeywords, first.text)
+}
+
+// Returns the position of the first parenthesis that is not closed, or of the first closing parenthesis without an
+// opening one, or -1 if all of them are balanced
+func findUnbalancedParenthesis(tokens []sqlToken) int {
+	open := make([]int, 0)
+	for _, t := range tokens {
+		switch parenthesisDelta(t) {
+		case 1:
+			open = append(open, t.start)
+		case -1:
+			if len(open) == 0 {
+				return t.start
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	if len(open) > 0 {
+		return open[0]
+	}
+	return -1
+}
+
+// Checks a statement, or a subquery, in the context of the entities of `outer`. `ctes` has the names of the common
+// table expressions that can be read
+func (c *staticQueryChecker) checkStatement(tokens []sqlToken, outer *queryScope, ctes map[string]bool) {
+	tokens = trimStatement(tokens)
+	if len(tokens) == 0 {
+		return
+	}
+	first := tokens[0]
+	switch {
+	case isKeyword(first, "WITH"):
+		c.checkWith(tokens, outer, ctes)
+	case isKeyword(first, "INSERT"):
+		c.checkInsert(tokens, ctes)
+	case isKeyword(first, "UPDATE") || isKeyword(first, "DELETE"):
+		c.checkUpdateOrDelete(tokens, ctes)
+	case isKeyword(first, "TABLE"):
+		schemaName, entityName, position, _ := parseQualifiedName(tokens, 1)
+		c.newScopeItem(schemaName, entityName, "", position, ctes)
+	case isKeyword(first, "SELECT") || isSymbol(first, "("):
+		c.checkSelect(tokens, outer, ctes)
+	}
+}
+
+// Checks the common table expressions of a statement and then the statement that uses them
+func (c *staticQueryChecker) checkWith(tokens []sqlToken, outer *queryScope, ctes map[string]bool) {
+	visible := make(map[string]bool)
+	for name := range ctes {
+		visible[name] = true
+	}
+
+	i := 1
+	ok := true
+	if i < len(tokens) && isKeyword(tokens[i], "RECURSIVE") {
+		i++
+	}
+	for i < len(tokens) && tokens[i].kind == identifierToken {
+		visible[strings.ToLower(tokens[i].text)] = true
+		i++
+		if i < len(tokens) && isSymbol(tokens[i], "(") {
+			if _, i, ok = c.getGroupTokens(tokens, i); !ok {
+				return
+			}
+		}
+		for i < len(tokens) && (isKeyword(tokens[i], "AS") || isKeyword(tokens[i], "NOT") ||
+			isKeyword(tokens[i], "MATERIALIZED")) {
+			i++
+		}
+		if i >= len(tokens) || !isSymbol(tokens[i], "(") {
+			return
+		}
+		body, end, ok := c.getGroupTokens(tokens, i)
+		if !ok {
+			return
+		}
+		c.checkStatement(body, outer, visible)
+		i = end
+		if i < len(tokens) && isSymbol(tokens[i], ",") {
+			i++
+			continue
+		}
+		break
+	}
+	c.checkStatement(tokens[i:], outer, visible)
+}
+
+// Checks each select of a query with UNION, INTERSECT or EXCEPT
+func (c *staticQueryChecker) checkSelect(tokens []sqlToken, outer *queryScope, ctes map[string]bool) {
+	start := 0
+	depth := 0
+	for i := 0; i <= len(tokens); i++ {
+		if i == len(tokens) || (depth == 0 && (isKeyword(tokens[i], "UNION") || isKeyword(tokens[i], "INTERSECT") ||
+			isKeyword(tokens[i], "EXCEPT"))) {
+			branch := trimStatement(tokens[start:i])
+			if len(branch) > 0 && isKeyword(branch[0], "SELECT") {
+				c.checkSelectBranch(branch, outer, ctes)
+			} else if len(branch) > 0 && isKeyword(branch[0], "WITH") {
+				c.checkWith(branch, outer, ctes)
+			}
+			if i+1 < len(tokens) && (isKeyword(tokens[i+1], "ALL") || isKeyword(tokens[i+1], "DISTINCT")) {
+				i++
+			}
+			start = i + 1
+			continue
+		}
+		depth += parenthesisDelta(tokens[i])
+	}
+}
+
+// Checks a single select: the entities of its FROM clause and the columns and comparisons of all its clauses
+func (c *staticQueryChecker) checkSelectBranch(tokens []sqlToken, outer *queryScope, ctes map[string]bool) {
+	scope := &queryScope{outer: outer}
+	i := 1
+	var distinctOn []sqlToken
+	if i < len(tokens) && isKeyword(tokens[i], "DISTINCT") {
+		i++
+		if i+1 < len(tokens) && isKeyword(tokens[i], "ON") {
+			group, end, ok := c.getGroupTokens(tokens, i+1)
+			if !ok {
+				return
+			}
+			distinctOn = group
+			i = end
+		}
+	} else if i < len(tokens) && isKeyword(tokens[i], "ALL") {
+		i++
+	}
+
+	targetsEnd := findTopLevelKeyword(tokens, i, selectClauseKeywords)
+	fromEnd := targetsEnd
+	if targetsEnd < len(tokens) && isKeyword(tokens[targetsEnd], "FROM") {
+		fromEnd = findTopLevelKeyword(tokens, targetsEnd+1, selectClauseKeywords[1:])
+		c.checkFromClause(tokens[targetsEnd+1:fromEnd], scope, ctes)
+	}
+
+	expressions := make([][]sqlToken, 0)
+	for _, target := range splitTopLevel(tokens[i:targetsEnd], ",") {
+		expression, alias := splitTargetAlias(target)
+		if alias != "" {
+			scope.outputNames = append(scope.outputNames, alias)
+		}
+		expressions = append(expressions, expression)
+	}
+	c.checkExpression(distinctOn, scope, ctes)
+	for _, expression := range expressions {
+		c.checkExpression(expression, scope, ctes)
+	}
+	c.checkExpression(tokens[fromEnd:], scope, ctes)
+}
+
+// Adds the items of a FROM clause to `scope`, and checks its subqueries and join conditions
+func (c *staticQueryChecker) checkFromClause(tokens []sqlToken, scope *queryScope, ctes map[string]bool) {
+	for _, item := range parseFromClause(stripCasts(tokens)) {
+		if item.entityName == "" {
+			// Subqueries and functions
+			scope.items = append(scope.items, queryScopeItem{name: item.alias})
+		} else {
+			scope.items = append(scope.items,
+				c.newScopeItem(item.schemaName, item.entityName, item.alias, item.position, ctes))
+		}
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		if isSymbol(tokens[i], "(") {
+			group, end, ok := c.getGroupTokens(tokens, i)
+			if !ok {
+				return
+			}
+			if len(group) > 0 && (isKeyword(group[0], "SELECT") || isKeyword(group[0], "WITH")) {
+				c.checkStatement(group, scope, ctes)
+				i = end - 1
+			}
+			// Other groups, like parenthesised joins, are read token by token
+			continue
+		}
+		if !isKeyword(tokens[i], "ON") {
+			continue
+		}
+		// The condition ends at the next join, or at the parenthesis that closes a parenthesised join
+		depth := 0
+		end := i + 1
+		for ; end < len(tokens); end++ {
+			depth += parenthesisDelta(tokens[end])
+			if depth < 0 || (depth == 0 && (isSymbol(tokens[end], ",") ||
+				(!tokens[end].quoted && containsKeyword(joinKeywords, tokens[end].text)))) {
+				break
+			}
+		}
+		c.checkExpression(tokens[i+1:end], scope, ctes)
+		i = end - 1
+	}
+}
+
+// Checks the column references and comparisons of an expression, and the subqueries in it
+func (c *staticQueryChecker) checkExpression(tokens []sqlToken, scope *queryScope, ctes map[string]bool) {
+	remaining := make([]sqlToken, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		if isSymbol(tokens[i], "(") && i+1 < len(tokens) &&
+			(isKeyword(tokens[i+1], "SELECT") || isKeyword(tokens[i+1], "WITH")) {
+			subquery, end, ok := c.getGroupTokens(tokens, i)
+			if !ok {
+				return
+			}
+			c.checkStatement(subquery, scope, ctes)
+			i = end - 1
+			continue
+		}
+		remaining = append(remaining, tokens[i])
+	}
+
+	withoutCasts := stripCasts(remaining)
+	// map with position of a token --> token before it
+	previous := make(map[int]sqlToken)
+	for i := 1; i < len(withoutCasts); i++ {
+		previous[withoutCasts[i].start] = withoutCasts[i-1]
+	}
+	for _, reference := range collectColumnReferences(withoutCasts) {
+		if containsKeyword(checkerKeywords, reference.column) {
+			continue
+		}
+		// Names of windows and collations
+		if before, hasBefore := previous[reference.position]; hasBefore &&
+			(isKeyword(before, "OVER") || isKeyword(before, "COLLATE")) {
+			continue
+		}
+		c.checkReference(reference, scope)
+	}
+	c.checkComparisons(remaining, scope)
+}
+
+// Checks that a column reference points to a column of the entities in scope. References to entities whose columns
+// are not known are not checked
+func (c *staticQueryChecker) checkReference(reference columnReference, scope *queryScope) {
+	if reference.qualifier != "" {
+		for s := scope; s != nil; s = s.outer {
+			for _, item := range s.items {
+				if item.name != reference.qualifier {
+					continue
+				}
+				if item.entity != nil && findEntityColumn(*item.entity, reference.column) == nil {
+					c.addProblem(reference.position,
+						fmt.Sprintf("column %s.%s does not exist", reference.qualifier, reference.column))
+				}
+				return
+			}
+		}
+		c.addProblem(reference.position, fmt.Sprintf("missing FROM-clause entry for table %s", reference.qualifier))
+		return
+	}
+
+	for s := scope; s != nil; s = s.outer {
+		for _, item := range s.items {
+			if item.entity == nil || findEntityColumn(*item.entity, reference.column) != nil {
+				return
+			}
+		}
+		if containsName(s.outputNames, reference.column) {
+			return
+		}
+	}
+	c.addProblem(reference.position, fmt.Sprintf("column %s does not exist", reference.column))
+}
+
+// Checks that the operands of comparisons between plain column references and literals have comparable types
+func (c *staticQueryChecker) checkComparisons(tokens []sqlToken, scope *queryScope) {
+	for k, t := range tokens {
+		if t.kind != symbolToken || !containsName(comparisonOperators, t.text) {
+			continue
+		}
+		leftStart := k - 1
+		for leftStart >= 2 && isSymbol(tokens[leftStart-1], ".") && tokens[leftStart-2].kind == identifierToken {
+			leftStart -= 2
+		}
+		if leftStart < 0 {
+			continue
+		}
+		if leftStart > 0 {
+			before := tokens[leftStart-1]
+			if !isSymbol(before, "(") && !isSymbol(before, ",") &&
+				!(before.kind == identifierToken && !before.quoted && containsKeyword(comparisonPrefixKeywords, before.text)) {
+				continue
+			}
+		}
+		rightEnd := k + 2
+		for rightEnd+1 < len(tokens) && isSymbol(tokens[rightEnd], ".") && tokens[rightEnd+1].kind == identifierToken &&
+			tokens[k+1].kind == identifierToken {
+			rightEnd += 2
+		}
+		if rightEnd > len(tokens) {
+			continue
+		}
+		if rightEnd < len(tokens) {
+			after := tokens[rightEnd]
+			if after.kind == symbolToken && !isSymbol(after, ")") && !isSymbol(after, ",") {
+				continue
+			}
+		}
+
+		leftType, leftName := c.getOperandType(tokens[leftStart:k], scope)
+		rightType, rightName := c.getOperandType(tokens[k+1:rightEnd], scope)
+		leftGroup, rightGroup := getComparableTypeGroup(leftType), getComparableTypeGroup(rightType)
+		if leftGroup != "" && rightGroup != "" && leftGroup != rightGroup {
+			c.addProblem(t.start, fmt.Sprintf("cannot compare %s (%s) with %s (%s)", leftName, leftType, rightName,
+				rightType))
+		}
+	}
+}
+
+// Returns the data type of an operand of a comparison and how to name it in messages. The type is empty if the operand
+// is not a column reference or literal of known type
+func (c *staticQueryChecker) getOperandType(tokens []sqlToken, scope *queryScope) (string, string) {
+	if len(tokens) == 1 {
+		t := tokens[0]
+		switch {
+		case t.kind == numberToken && strings.ContainsAny(t.text, ".eE"):
+			return "numeric", t.text
+		case t.kind == numberToken:
+			return "integer", t.text
+		case isKeyword(t, "TRUE") || isKeyword(t, "FALSE"):
+			return "boolean", t.text
+		case t.kind != identifierToken || (!t.quoted && containsKeyword(expressionKeywords, t.text)):
+			return "", ""
+		}
+	}
+	if tokens[len(tokens)-1].kind != identifierToken {
+		return "", ""
+	}
+
+	reference := columnReference{column: strings.ToLower(tokens[len(tokens)-1].text)}
+	if len(tokens) >= 3 {
+		reference.qualifier = strings.ToLower(tokens[len(tokens)-3].text)
+	}
+	name := reference.column
+	if reference.qualifier != "" {
+		name = reference.qualifier + "." + name
+	}
+	for s := scope; s != nil; s = s.outer {
+		for _, item := range s.items {
+			if item.entity == nil || (reference.qualifier != "" && item.name != reference.qualifier) {
+				continue
+			}
+			if column := findEntityColumn(*item.entity, reference.column); column != nil {
+				return column.DataType, name
+			}
+		}
+	}
+	return "", name
+}
+
+// Checks the target entity of an insert, the columns it sets and the query that provides the rows
+func (c *staticQueryChecker) checkInsert(tokens []sqlToken, ctes map[string]bool) {
+	if len(tokens) < 3 || !isKeyword(tokens[1], "INTO") {
+		return
+	}
+	schemaName, entityName, position, i := parseQualifiedName(tokens, 2)
+	item := c.newScopeItem(schemaName, entityName, "", position, ctes)
+	if i < len(tokens) && isKeyword(tokens[i], "AS") {
+		i += 2
+	}
+	if i < len(tokens) && isSymbol(tokens[i], "(") && !(i+1 < len(tokens) &&
+		(isKeyword(tokens[i+1], "SELECT") || isKeyword(tokens[i+1], "WITH"))) {
+		columns, end, ok := c.getGroupTokens(tokens, i)
+		if !ok {
+			return
+		}
+		c.checkTargetColumns(columns, item)
+		i = end
+	}
+	if i >= len(tokens) {
+		return
+	}
+	rest := tokens[i:]
+	body := rest[:findTopLevelKeyword(rest, 0, []string{"ON", "RETURNING"})]
+	if len(body) > 0 && (isKeyword(body[0], "SELECT") || isKeyword(body[0], "WITH") || isSymbol(body[0], "(")) {
+		c.checkStatement(body, nil, ctes)
+	}
+}
+
+// Checks the target entity of an update or delete, the columns an update sets and the expressions of its clauses
+func (c *staticQueryChecker) checkUpdateOrDelete(tokens []sqlToken, ctes map[string]bool) {
+	i := 1
+	if isKeyword(tokens[0], "DELETE") {
+		if i >= len(tokens) || !isKeyword(tokens[i], "FROM") {
+			return
+		}
+		i++
+	}
+	if i < len(tokens) && isKeyword(tokens[i], "ONLY") {
+		i++
+	}
+	schemaName, entityName, position, i := parseQualifiedName(tokens, i)
+	if i < len(tokens) && isKeyword(tokens[i], "AS") {
+		i++
+	}
+	alias := ""
+	if i < len(tokens) && tokens[i].kind == identifierToken &&
+		(tokens[i].quoted || !containsKeyword([]string{"SET", "USING", "WHERE", "RETURNING"}, tokens[i].text)) {
+		alias = strings.ToLower(tokens[i].text)
+		i++
+	}
+	target := c.newScopeItem(schemaName, entityName, alias, position, ctes)
+	scope := &queryScope{items: []queryScopeItem{target}}
+
+	clauseKeywords := []string{"FROM", "USING", "WHERE", "RETURNING"}
+	// The values of an update can reference the entities of its FROM clause, so they are checked after it
+	values := make([][]sqlToken, 0)
+	if i < len(tokens) && isKeyword(tokens[i], "SET") {
+		setEnd := findTopLevelKeyword(tokens, i+1, clauseKeywords)
+		for _, assignment := range splitTopLevel(tokens[i+1:setEnd], ",") {
+			equals := findTopLevelSymbol(assignment, "=")
+			if equals < 0 {
+				continue
+			}
+			columns := assignment[:equals]
+			if len(columns) > 0 && isSymbol(columns[0], "(") {
+				group, _, ok := c.getGroupTokens(columns, 0)
+				if !ok {
+					return
+				}
+				columns = group
+			}
+			c.checkTargetColumns(columns, target)
+			values = append(values, assignment[equals+1:])
+		}
+		i = setEnd
+	}
+	if i < len(tokens) && (isKeyword(tokens[i], "FROM") || isKeyword(tokens[i], "USING")) {
+		end := findTopLevelKeyword(tokens, i+1, []string{"WHERE", "RETURNING"})
+		c.checkFromClause(tokens[i+1:end], scope, ctes)
+		i = end
+	}
+	for _, value := range values {
+		c.checkExpression(value, scope, ctes)
+	}
+	c.checkExpression(tokens[i:], scope, ctes)
+}
+
+// Checks that the columns in a list of names, separated by commas, are columns of the target of an insert or update
+func (c *staticQueryChecker) checkTargetColumns(tokens []sqlToken, target queryScopeItem) {
+	if target.entity == nil {
+		return
+	}
+	for _, column := range splitTopLevel(tokens, ",") {
+		// Fields of composite columns and elements of arrays are set through the column
+		if len(column) == 0 || column[0].kind != identifierToken {
+			continue
+		}
+		name := strings.ToLower(column[0].text)
+		if findEntityColumn(*target.entity, name) == nil {
+			c.addProblem(column[0].start, fmt.Sprintf("column %s of relation %s does not exist", name, target.entity.Name))
+		}
+	}
+}
+
+// Returns the item of a scope for an entity read by a query. Entities that are not common table expressions and are
+// not in the description are reported, unless they are in a catalog schema or a schema that is not described
+func (c *staticQueryChecker) newScopeItem(
+	schemaName string, entityName string, alias string, position int, ctes map[string]bool) queryScopeItem {
+	item := queryScopeItem{name: alias}
+	if item.name == "" {
+		item.name = entityName
+	}
+	if entityName == "" || (schemaName == "" && ctes[entityName]) || containsName(catalogSchemas, schemaName) ||
+		(schemaName == "" && strings.HasPrefix(entityName, "pg_")) {
+		return item
+	}
+	if _, schemaExists := c.dataMap[schemaName]; schemaName != "" && !schemaExists {
+		return item
+	}
+
+	matches := make([]model.Entity, 0)
+	if schemaName != "" {
+		if entity, found := c.dataMap[schemaName][entityName]; found {
+			matches = append(matches, entity)
+		}
+	} else if entity, found := c.dataMap["public"][entityName]; found {
+		matches = append(matches, entity)
+	} else {
+		for _, entityMap := range c.dataMap {
+			if entity, found := entityMap[entityName]; found {
+				matches = append(matches, entity)
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		name := entityName
+		if schemaName != "" {
+			name = schemaName + "." + entityName
+		}
+		c.addProblem(position, fmt.Sprintf("relation %s does not exist", name))
+	case 1:
+		item.entity = &matches[0]
+	}
+	// An entity in several schemas depends on the search path, so its columns are not known
+	return item
+}
+
+// Returns the tokens inside the parenthesis group that opens at position `start` and the position after it. If the
+// group is not closed, the problem is added and false is returned
+func (c *staticQueryChecker) getGroupTokens(tokens []sqlToken, start int) ([]sqlToken, int, bool) {
+	end, closed := findParenthesisGroupEnd(tokens, start)
+	if !closed {
+		c.addProblem(tokens[start].start, unbalancedParenthesisMessage)
+		return nil, end, false
+	}
+	return tokens[start+1 : end-1], end, true
+}
+
+// Adds a problem, unless the same problem was already found
+func (c *staticQueryChecker) addProblem(position int, message string) {
+	for _, p := range c.problems {
+		if p.position == position && p.message == message {
+			return
+		}
+	}
+	c.problems = append(c.problems, queryProblem{position, message})
+}
+
+// Reads a name, optionally qualified by a schema, at position `i`. Returns the schema and entity names, the position
+// of the name in the statement and the position of the next token
+func parseQualifiedName(tokens []sqlToken, i int) (string, string, int, int) {
+	if i >= len(tokens) || tokens[i].kind != identifierToken {
+		return "", "", -1, i
+	}
+	position := tokens[i].start
+	parts := []string{strings.ToLower(tokens[i].text)}
+	for i+2 < len(tokens) && isSymbol(tokens[i+1], ".") && tokens[i+2].kind == identifierToken {
+		parts = append(parts, strings.ToLower(tokens[i+2].text))
+		i += 2
+	}
+	if len(parts) == 1 {
+		return "", parts[0], position, i + 1
+	}
+	return parts[len(parts)-2], parts[len(parts)-1], position, i + 1
+}
+
+// Splits an item of the target list of a select into its expression and its alias, with or without AS. The alias is
+// empty if there is none
+func splitTargetAlias(tokens []sqlToken) ([]sqlToken, string) {
+	n := len(tokens)
+	if n >= 3 && isKeyword(tokens[n-2], "AS") {
+		return tokens[:n-2], strings.ToLower(tokens[n-1].text)
+	}
+	if n >= 2 {
+		last, before := tokens[n-1], tokens[n-2]
+		isName := last.kind == identifierToken && (last.quoted || !containsKeyword(expressionKeywords, last.text))
+		endsExpression := before.kind == identifierToken || before.kind == numberToken || before.kind == stringToken ||
+			isSymbol(before, ")") || isSymbol(before, "]")
+		if isName && endsExpression {
+			return tokens[:n-1], strings.ToLower(last.text)
+		}
+	}
+	return tokens, ""
+}
+
+// Returns the position of the first symbol found at the top level, or -1 if there is none
+func findTopLevelSymbol(tokens []sqlToken, symbol string) int {
+	depth := 0
+	for i, t := range tokens {
+		if depth == 0 && isSymbol(t, symbol) {
+			return i
+		}
+		depth += parenthesisDelta(t)
+	}
+	return -1
+}
+
+// Returns the column of an entity with the given name, or nil if the entity does not have it
+func findEntityColumn(entity model.Entity, columnName string) *model.Column {
+	for i := range entity.Columns {
+		if entity.Columns[i].Name == columnName {
+			return &entity.Columns[i]
+		}
+	}
+	return nil
+}
+
+// Returns the group of types whose values can be compared with each other (numbers, texts, booleans, uuids, dates and
+// timestamps) or an empty string for other types, which are not checked
+func getComparableTypeGroup(dataType string) string {
+	baseType := strings.TrimSpace(strings.SplitN(strings.ToLower(dataType), "(", 2)[0])
+	switch {
+	case dataType == "" || strings.HasSuffix(dataType, "[]"):
+		return ""
+	case isNumericDataType(dataType):
+		return "number"
+	case isTextDataType(dataType):
+		return "text"
+	case baseType == "boolean" || baseType == "uuid":
+		return baseType
+	case baseType == "date" || strings.HasPrefix(baseType, "timestamp"):
+		return "datetime"
+	}
+	return ""
+}
+
+// Sorts issues by file and line, keeping the order of the issues of the same line
+func sortQueryIssues(issues []model.QueryIssue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].FileName != issues[j].FileName {
+			return issues[i].FileName < issues[j].FileName
+		}
+		return issues[i].Line < issues[j].Line
+	})
+}
@@ -0,0 +1,169 @@
+package extractor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
+)
+
+// A description with patient and model tables in the public schema
+var checkedDescription = model.DatabaseDescription{Schemas: []model.Schema{{Name: "public", Entities: []model.Entity{
+	{SchemaName: "public", Name: "patient", EntityType: model.Table, Columns: []model.Column{
+		{Name: "id", DataType: "integer"},
+		{Name: "name", DataType: "text"},
+		{Name: "created", DataType: "timestamp without time zone"},
+	}},
+	{SchemaName: "public", Name: "model", EntityType: model.Table, Columns: []model.Column{
+		{Name: "id", DataType: "integer"},
+		{Name: "patient_id", DataType: "integer"},
+		{Name: "code", DataType: "character varying(10)"},
+	}},
+}}}}
+
+func TestCheckQueriesAgainstDescription(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		expected []model.QueryIssue
+	}{
+		{
+			name: "valid queries",
+			sql: `-- Patients and their models
+SELECT p.id, p.name AS patient_name, m.code
+FROM patient p
+LEFT JOIN model m ON m.patient_id = p.id
+WHERE p.created > now()
+ORDER BY patient_name;
+
+WITH recent AS (SELECT id FROM patient) SELECT r.id, r.anything FROM recent r;
+SELECT p.id::text = '1' FROM patient p;
+UPDATE patient SET name = 'x' WHERE id = 1;
+CREATE INDEX patient_name ON patient (name);`,
+			expected: []model.QueryIssue{},
+		},
+		{
+			name: "missing entities and columns",
+			sql: `SELECT p.id
+FROM patient p
+WHERE p.nme = 'x';
+SELECT id FROM nosuch;
+SELECT a.id FROM patient;
+INSERT INTO patient (id, nam) SELECT id, code FROM model;`,
+			expected: []model.QueryIssue{
+				{Line: 3, Message: "column p.nme does not exist"},
+				{Line: 4, Message: "relation nosuch does not exist"},
+				{Line: 5, Message: "missing FROM-clause entry for table a"},
+				{Line: 6, Message: "column nam of relation patient does not exist"},
+			},
+		},
+		{
+			name: "incompatible types",
+			sql:  "SELECT id FROM model WHERE code = 1 AND patient_id = id",
+			expected: []model.QueryIssue{
+				{Line: 1, Message: "cannot compare code (character varying(10)) with 1 (integer)"},
+			},
+		},
+		{
+			name: "unclosed common table expression",
+			sql:  "SELECT 1;\nWITH x AS (",
+			expected: []model.QueryIssue{
+				{Line: 2, Message: unbalancedParenthesisMessage},
+			},
+		},
+		{
+			name: "unclosed subquery",
+			sql:  "SELECT * FROM (",
+			expected: []model.QueryIssue{
+				{Line: 1, Message: unbalancedParenthesisMessage},
+			},
+		},
+		{
+			name: "closing parenthesis without opening one",
+			sql:  "SELECT id)\nFROM patient",
+			expected: []model.QueryIssue{
+				{Line: 1, Message: unbalancedParenthesisMessage},
+			},
+		},
+		{
+			name: "unterminated string",
+			sql:  "SELECT 'abc FROM patient",
+			expected: []model.QueryIssue{
+				{Line: 1, Message: unterminatedTokenMessage},
+			},
+		},
+		{
+			name: "values lists, functions and time zones",
+			sql: `SELECT t.id FROM (VALUES (1), (2)) AS t(id);
+SELECT v.a FROM (VALUES (1, 2)) v(a, b) JOIN patient p ON p.id = v.a;
+SELECT r.id FROM ROWS FROM (generate_series(1, 2)) AS r(id);
+SELECT g.n FROM generate_series(1, 3) WITH ORDINALITY AS g(v, n);
+SELECT id FROM patient WHERE created AT TIME ZONE 'UTC' > now()`,
+			expected: []model.QueryIssue{},
+		},
+		{
+			name:     "escape and dollar-quoted strings",
+			sql:      "SELECT E'it\\'s' AS a, $$a(b$$ AS b, $x$c;d$x$ AS c FROM patient;\nSELECT name FROM patient",
+			expected: []model.QueryIssue{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), "query.sql")
+			if err := os.WriteFile(fileName, []byte(test.sql), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			for i := range test.expected {
+				test.expected[i].FileName = fileName
+			}
+			issues := CheckQueriesAgainstDescription(checkedDescription, []string{fileName})
+			if !reflect.DeepEqual(issues, test.expected) {
+				t.Errorf("CheckQueriesAgainstDescription() = %+v, expected %+v", issues, test.expected)
+			}
+		})
+	}
+}
+
+func TestSplitSqlStatements(t *testing.T) {
+	text := "-- first\nSELECT 1;\n\nSELECT ';'\n  FROM patient;;\nSELECT $$;$$"
+	expected := []sqlStatement{
+		{fileName: "a.sql", text: "SELECT 1", line: 2},
+		{fileName: "a.sql", text: "SELECT ';'\n  FROM patient", line: 4},
+		{fileName: "a.sql", text: "SELECT $$;$$", line: 6},
+	}
+	statements := splitSqlStatements("a.sql", text)
+	if !reflect.DeepEqual(statements, expected) {
+		t.Errorf("splitSqlStatements() = %+v, expected %+v", statements, expected)
+	}
+	if line := statements[1].lineAt(15); line != 5 {
+		t.Errorf("expected line 5 at position 15 of the second statement, got %d", line)
+	}
+}
+
+func TestStaticQueryCheckerWithUnclosedGroups(t *testing.T) {
+	// The parentheses are not checked before, so each clause must stop at the unclosed group
+	for _, text := range []string{
+		"WITH x AS (",
+		"WITH x (a, b",
+		"SELECT * FROM (",
+		"SELECT DISTINCT ON (id FROM patient",
+		"SELECT id FROM patient WHERE id IN (SELECT id FROM model",
+		"INSERT INTO patient (id, name",
+	} {
+		tokens, ok := tokenizeSQL(text)
+		if !ok {
+			t.Fatalf("could not tokenize %q", text)
+		}
+		checker := staticQueryChecker{dataMap: map[string]map[string]model.Entity{}}
+		checker.checkStatement(tokens, nil, make(map[string]bool))
+		found := false
+		for _, p := range checker.problems {
+			found = found || p.message == unbalancedParenthesisMessage
+		}
+		if !found {
+			t.Errorf("expected an unbalanced parenthesis in %q, got %+v", text, checker.problems)
+		}
+	}
+}
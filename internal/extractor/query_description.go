@@ -0,0 +1,329 @@
+package extractor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PDCMFinder/db-descriptor/pkg/connector"
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
+)
+
+// Names of the types reported by the driver and the names the database gives them in the description of columns
+var driverDataTypeNames = map[string]string{
+	"INT2":        "smallint",
+	"INT4":        "integer",
+	"INT8":        "bigint",
+	"FLOAT4":      "real",
+	"FLOAT8":      "double precision",
+	"BOOL":        "boolean",
+	"VARCHAR":     "character varying",
+	"BPCHAR":      "character",
+	"TIMESTAMP":   "timestamp without time zone",
+	"TIMESTAMPTZ": "timestamp with time zone",
+	"TIME":        "time without time zone",
+	"TIMETZ":      "time with time zone",
+}
+
+// Name of the prepared statement used to validate queries before describing them
+const describeStatementName = "db_descriptor_describe"
+
+// The SQL text of a query with the name it is described with
+type namedQuery struct {
+	name string
+	text string
+}
+
+/*
+Returns the description of the queries in `input.QueryFiles` and `input.QueryTexts` as entities of type query.
+
+The queries are not executed. In a read-only transaction, each query is prepared (see prepareQuery), which checks it
+without running it, and then wrapped in a statement that returns no rows, so its columns are taken from the metadata of
+an empty result. Texts with more than one statement are rejected. The query is also parsed to find the entities
+it reads and the lineage of its columns. Columns that are a plain copy of a column of an entity have the exact data
+type of that column and are not nullable when the column is not nullable and its entity is not on the nullable side
+of an outer join. Queries that cannot be described are logged and skipped.
+*/
+func describeQueries(
+	dataMap map[string]map[string]model.Entity,
+	dBConnector connector.DBConnector,
+	input connector.Input,
+	db *sql.DB) []model.Entity {
+	queries := make([]model.Entity, 0)
+	for _, query := range readQueries(input.QueryFiles, input.QueryTexts) {
+		entity, err := describeQuery(query, dataMap, dBConnector, db)
+		if err != nil {
+			log.Printf("Could not describe the query %s. Error: %s", query.name, err.Error())
+			continue
+		}
+		queries = append(queries, entity)
+	}
+	return queries
+}
+
+// Reads the queries in SQL files, or in the .sql files of directories, and names them after their files. Queries in
+// `texts` are named query_1, query_2... Files that cannot be read stop the program
+func readQueries(fileNames []string, texts []string) []namedQuery {
+	queries := make([]namedQuery, 0)
+	for _, fileName := range expandSqlFileNames(fileNames) {
+		content, err := os.ReadFile(fileName)
+		if err != nil {
+			log.Fatal("Could not read the query file. Error: ", err)
+		}
+		name := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+		queries = append(queries, namedQuery{name: name, text: string(content)})
+	}
+	for i, text := range texts {
+		queries = append(queries, namedQuery{name: "query_" + strconv.Itoa(i+1), text: text})
+	}
+	return queries
+}
+
+// Replaces the directories in `fileNames` with the .sql files they contain, sorted by name
+func expandSqlFileNames(fileNames []string) []string {
+	expanded := make([]string, 0, len(fileNames))
+	for _, fileName := range fileNames {
+		info, err := os.Stat(fileName)
+		if err != nil || !info.IsDir() {
+			expanded = append(expanded, fileName)
+			continue
+		}
+		matches, _ := filepath.Glob(filepath.Join(fileName, "*.sql"))
+		sort.Strings(matches)
+		expanded = append(expanded, matches...)
+	}
+	return expanded
+}
+
+// Describes the result of a query without executing it
+func describeQuery(
+	query namedQuery,
+	dataMap map[string]map[string]model.Entity,
+	dBConnector connector.DBConnector,
+	db *sql.DB) (model.Entity, error) {
+	text := trimQueryText(query.text)
+	if tokens, ok := tokenizeSQL(text); ok {
+		for _, t := range tokens {
+			if isSymbol(t, ";") {
+				return model.Entity{}, errors.New("the query has more than one statement")
+			}
+		}
+	}
+	columnTypes, err := getQueryColumnTypes(text, dBConnector, db)
+	if err != nil {
+		return model.Entity{}, err
+	}
+
+	entity := model.Entity{
+		Name:           query.name,
+		EntityType:     model.Query,
+		Comment:        getQueryComment(query.text),
+		ViewDefinition: text,
+		Dependencies:   make([]model.EntityReference, 0)}
+	for _, columnType := range columnTypes {
+		entity.Columns = append(entity.Columns, model.Column{
+			EntityName: query.name,
+			Name:       columnType.Name(),
+			DataType:   formatColumnType(columnType),
+			IsNullable: true})
+	}
+
+	branches, ok := parseSelectQuery(text)
+	if !ok {
+		return entity, nil
+	}
+	// map with schema name --> entity name --> true, for the entities already added as dependencies
+	seen := make(map[string]map[string]bool)
+	usages := make([]model.ColumnLineage, 0)
+	for _, branch := range branches {
+		for _, item := range branch.fromItems {
+			source, found := findQueryEntity(dataMap, item)
+			if !found || seen[source.SchemaName][source.Name] {
+				continue
+			}
+			if seen[source.SchemaName] == nil {
+				seen[source.SchemaName] = make(map[string]bool)
+			}
+			seen[source.SchemaName][source.Name] = true
+			entity.Dependencies = append(entity.Dependencies, model.EntityReference{
+				SchemaName: source.SchemaName, EntityName: source.Name, EntityType: source.EntityType})
+			for _, c := range source.Columns {
+				usages = append(usages, model.ColumnLineage{
+					SourceSchemaName: source.SchemaName, SourceEntityName: source.Name, SourceColumnName: c.Name})
+			}
+		}
+	}
+
+	// Targets like * cannot be matched to the columns by their position
+	for _, branch := range branches {
+		if len(branch.targets) != len(entity.Columns) {
+			return entity, nil
+		}
+	}
+	lineage := buildViewColumnLineage(entity, usages)
+	for i := range entity.Columns {
+		column := &entity.Columns[i]
+		column.Lineage = lineage[i]
+		if len(branches) == 1 && len(column.Lineage) == 1 && column.Lineage[0].IsPassthrough {
+			source := column.Lineage[0]
+			sourceColumn := findColumn(dataMap[source.SourceSchemaName][source.SourceEntityName], source.SourceColumnName)
+			column.DataType = sourceColumn.DataType
+			column.IsNullable = sourceColumn.IsNullable || isOnNullableSide(branches[0].fromItems, source)
+		}
+	}
+	return entity, nil
+}
+
+// Returns the columns of the result of a query without reading its rows, in a read-only transaction that is rolled
+// back. The query is prepared first, and its columns are then read from a prepared statement that returns no rows
+func getQueryColumnTypes(query string, dBConnector connector.DBConnector, db *sql.DB) ([]*sql.ColumnType, error) {
+	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := prepareQuery(tx, dBConnector, describeStatementName, query); err != nil {
+		return nil, err
+	}
+	stmt, err := tx.Prepare(dBConnector.GetQueryShapeQueryStatement(query))
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	rows, err := stmt.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return rows.ColumnTypes()
+}
+
+/*
+Prepares a query in a transaction, so the database checks that it is valid, with its entities, columns and types,
+without executing it. The prepared statement is removed afterwards.
+
+The statement that prepares the query is itself sent as a prepared statement, through the extended protocol of the
+database, which rejects texts with more than one statement, so nothing after the query can run.
+*/
+func prepareQuery(tx *sql.Tx, dBConnector connector.DBConnector, statementName string, query string) error {
+	stmt, err := tx.Prepare(dBConnector.GetPrepareQueryStatement(statementName, query))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	if _, err := stmt.Exec(); err != nil {
+		return err
+	}
+	_, err = tx.Exec(dBConnector.GetDeallocateQueryStatement(statementName))
+	return err
+}
+
+// Returns the name of the data type of a column of a result, like the database names the types of the columns of
+// tables
+func formatColumnType(columnType *sql.ColumnType) string {
+	driverName := columnType.DatabaseTypeName()
+	if driverName == "" {
+		return "unknown"
+	}
+	suffix := ""
+	if strings.HasPrefix(driverName, "_") {
+		driverName = driverName[1:]
+		suffix = "[]"
+	}
+	name, isKnown := driverDataTypeNames[driverName]
+	if !isKnown {
+		name = strings.ToLower(driverName)
+	}
+
+	// Lengths and precisions are only known when the type has them declared
+	if length, ok := columnType.Length(); ok && (driverName == "VARCHAR" || driverName == "BPCHAR") && length > 0 {
+		name = fmt.Sprintf("%s(%d)", name, length)
+	}
+	if precision, scale, ok := columnType.DecimalSize(); ok && precision > 0 && precision <= 1000 {
+		name = fmt.Sprintf("%s(%d,%d)", name, precision, scale)
+	}
+	return name + suffix
+}
+
+// Returns the text of the comments before the first statement of a SQL text, which usually describe it
+func getQueryComment(text string) string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "--") {
+			if line == "" && len(lines) == 0 {
+				continue
+			}
+			break
+		}
+		lines = append(lines, strings.TrimSpace(strings.TrimPrefix(line, "--")))
+	}
+	return strings.Join(lines, " ")
+}
+
+// Removes the semicolons that end a SQL text and the spaces around it
+func trimQueryText(text string) string {
+	tokens, ok := tokenizeSQL(text)
+	if !ok {
+		return strings.TrimSpace(text)
+	}
+	runes := []rune(text)
+	for len(tokens) > 0 && isSymbol(tokens[len(tokens)-1], ";") {
+		runes = runes[:tokens[len(tokens)-1].start]
+		tokens = tokens[:len(tokens)-1]
+	}
+	return strings.TrimSpace(string(runes))
+}
+
+// Finds the entity read by an item of a FROM clause. Items without schema are searched in all the schemas, preferring
+// public when the name is in several of them
+func findQueryEntity(dataMap map[string]map[string]model.Entity, item fromItem) (model.Entity, bool) {
+	if item.entityName == "" {
+		return model.Entity{}, false
+	}
+	if item.schemaName != "" {
+		entity, found := dataMap[item.schemaName][item.entityName]
+		return entity, found
+	}
+	if entity, found := dataMap["public"][item.entityName]; found {
+		return entity, true
+	}
+	matches := make([]model.Entity, 0)
+	for _, entityMap := range dataMap {
+		if entity, found := entityMap[item.entityName]; found {
+			matches = append(matches, entity)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], true
+	}
+	return model.Entity{}, false
+}
+
+func findColumn(entity model.Entity, columnName string) model.Column {
+	for _, c := range entity.Columns {
+		if c.Name == columnName {
+			return c
+		}
+	}
+	return model.Column{IsNullable: true}
+}
+
+// Returns true if the entity of a source column is read by an item on the nullable side of an outer join
+func isOnNullableSide(fromItems []fromItem, source model.ColumnLineage) bool {
+	for _, item := range fromItems {
+		if item.isNullable && item.entityName == source.SourceEntityName &&
+			(item.schemaName == "" || item.schemaName == source.SourceSchemaName) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+package extractor
+
+import (
+	"database/sql"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/PDCMFinder/db-descriptor/pkg/connector"
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
+)
+
+// Minimum number of fks that must reference a table for it to be detected as a reference table
+const minReferenceTableReferences = 2
+
+// Names of the columns that usually describe the codes of a reference table, by preference
+var referenceLabelColumnNames = []string{"label", "name", "description", "term", "title", "display_name", "value"}
+
+/*
+Populates the reference tables in `dataMap` with their rows, and the columns that reference them with their codes and
+labels.
+
+Reference tables are the tables that match the patterns in `input.ReferenceTables` and, if detection is enabled, the
+tables with at most `input.ReferenceMaxRows` rows that are referenced by several fks and have a code column (a single
+column key) and a label column. The rows of configured tables are truncated to `input.ReferenceMaxRows`, while tables
+with more rows are not detected.
+*/
+func populateReferenceData(
+	dataMap map[string]map[string]model.Entity, dBConnector connector.DBConnector, input connector.Input, db *sql.DB) {
+	for _, e := range getEntitiesWithStorage(dataMap, false) {
+		entity := dataMap[e.SchemaName][e.EntityName]
+		isConfigured := matchesEntityPattern(input.ReferenceTables, entity.SchemaName, entity.Name)
+		if !isConfigured && !(input.DetectReferenceTables && isReferenceTableCandidate(entity, input.ReferenceMaxRows)) {
+			continue
+		}
+
+		columnNames := make([]string, 0, len(entity.Columns))
+		for _, c := range entity.Columns {
+			columnNames = append(columnNames, c.Name)
+		}
+		// One more row than the maximum is requested to know if the table has too many
+		rowLimit := 0
+		if input.ReferenceMaxRows > 0 {
+			rowLimit = input.ReferenceMaxRows + 1
+		}
+		schemaName, entityName := getEntityQueryNames(entity)
+		queryStatement := dBConnector.GetSampleRowsQueryStatement(
+			schemaName, entityName, getColumnQueryNames(entity, columnNames), 0, rowLimit)
+		rows, err := getSampleRowsList(queryStatement, len(columnNames), db)
+		if err != nil {
+			log.Printf("Could not read the rows of %s.%s. Error: %s", entity.SchemaName, entity.Name, err.Error())
+			continue
+		}
+
+		isTruncated := input.ReferenceMaxRows > 0 && len(rows) > input.ReferenceMaxRows
+		if isTruncated {
+			if !isConfigured {
+				continue
+			}
+			rows = rows[:input.ReferenceMaxRows]
+		}
+
+		codeColumnName := getReferenceCodeColumnName(entity)
+		if codeColumnName == "" && len(entity.Columns) > 0 {
+			codeColumnName = entity.Columns[0].Name
+		}
+		sortRowsByColumn(rows, entity, codeColumnName)
+
+		entity.ReferenceData = &model.ReferenceData{
+			CodeColumnName:  codeColumnName,
+			LabelColumnName: getReferenceLabelColumnName(entity, codeColumnName),
+			IsConfigured:    isConfigured,
+			Rows:            model.RowSet{ColumnNames: columnNames, Rows: rows, IsTruncated: isTruncated}}
+		dataMap[e.SchemaName][e.EntityName] = entity
+	}
+
+	applyReferenceValues(dataMap)
+}
+
+// Returns true if the entity looks like a reference table: a table referenced by several fks, with a code and a label
+// column, and no more than `maxRows` rows as far as it is known before reading them
+func isReferenceTableCandidate(entity model.Entity, maxRows int) bool {
+	if entity.EntityType != model.Table && entity.EntityType != model.PartitionedTable {
+		return false
+	}
+	if len(entity.ReferencedBy) < minReferenceTableReferences {
+		return false
+	}
+	codeColumnName := getReferenceCodeColumnName(entity)
+	if codeColumnName == "" || getReferenceLabelColumnName(entity, codeColumnName) == "" {
+		return false
+	}
+
+	if entity.Storage != nil && maxRows > 0 {
+		if entity.Storage.ExactRowCount != nil && *entity.Storage.ExactRowCount > int64(maxRows) {
+			return false
+		}
+		if entity.Storage.EstimatedRowCount != nil && *entity.Storage.EstimatedRowCount > int64(maxRows) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the name of the column that identifies the rows of a reference table: the primary key if it has a single
+// column or else the first single column unique key. Empty if there is none
+func getReferenceCodeColumnName(entity model.Entity) string {
+	codeColumnName := ""
+	for _, uniqueKey := range entity.UniqueKeys {
+		if len(uniqueKey.ColumnNames) != 1 {
+			continue
+		}
+		if uniqueKey.IsPrimaryKey {
+			return uniqueKey.ColumnNames[0]
+		}
+		if codeColumnName == "" {
+			codeColumnName = uniqueKey.ColumnNames[0]
+		}
+	}
+	return codeColumnName
+}
+
+// Returns the name of the text column that describes the codes of a reference table, preferring the usual names of
+// such columns. Empty if there is none
+func getReferenceLabelColumnName(entity model.Entity, codeColumnName string) string {
+	candidates := make([]string, 0)
+	for _, c := range entity.Columns {
+		if c.Name != codeColumnName && isTextDataType(c.DataType) {
+			candidates = append(candidates, c.Name)
+		}
+	}
+	for _, name := range referenceLabelColumnNames {
+		if containsName(candidates, name) {
+			return name
+		}
+	}
+	if len(candidates) > 0 {
+		return candidates[0]
+	}
+	return ""
+}
+
+// Sets the codes and labels of the reference tables in `dataMap` on the columns of single column fks that reference
+// them. When all the rows of the reference table were read, the codes are also the allowed values of the column
+func applyReferenceValues(dataMap map[string]map[string]model.Entity) {
+	for schemaName, entityMap := range dataMap {
+		for entityName, entity := range entityMap {
+			for _, r := range entity.Relations {
+				if len(r.ColumnPairs) != 1 {
+					continue
+				}
+				target, targetExists := dataMap[strings.ToLower(r.ForeignEntitySchema)][strings.ToLower(r.ForeignEntityName)]
+				if !targetExists || target.ReferenceData == nil {
+					continue
+				}
+
+				values := getReferenceValues(*target.ReferenceData, strings.ToLower(r.ColumnPairs[0].ForeignColumnName))
+				for i := range entity.Columns {
+					column := &entity.Columns[i]
+					if column.Name != r.ColumnPairs[0].ColumnName || values == nil {
+						continue
+					}
+					column.ReferenceValues = values
+					if column.AllowedValues == nil && !target.ReferenceData.Rows.IsTruncated {
+						column.AllowedValues = make([]string, 0, len(values))
+						for _, v := range values {
+							column.AllowedValues = append(column.AllowedValues, v.Code)
+						}
+					}
+				}
+			}
+			dataMap[schemaName][entityName] = entity
+		}
+	}
+}
+
+// Returns the values of the column `codeColumnName` in the rows of a reference table with their labels, or nil if the
+// rows do not have the column
+func getReferenceValues(referenceData model.ReferenceData, codeColumnName string) []model.ReferenceValue {
+	codeIndex, labelIndex := -1, -1
+	for i, name := range referenceData.Rows.ColumnNames {
+		if name == codeColumnName {
+			codeIndex = i
+		}
+		if name == referenceData.LabelColumnName {
+			labelIndex = i
+		}
+	}
+	if codeIndex < 0 {
+		return nil
+	}
+
+	values := make([]model.ReferenceValue, 0, len(referenceData.Rows.Rows))
+	for _, row := range referenceData.Rows.Rows {
+		if row[codeIndex] == nil {
+			continue
+		}
+		value := model.ReferenceValue{Code: *row[codeIndex]}
+		if labelIndex >= 0 && row[labelIndex] != nil {
+			value.Label = *row[labelIndex]
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// Sorts rows read from `entity` by the values of one of its columns, with nulls at the end
+func sortRowsByColumn(rows [][]*string, entity model.Entity, columnName string) {
+	index := -1
+	numeric := false
+	for i, c := range entity.Columns {
+		if c.Name == columnName {
+			index = i
+			numeric = isNumericDataType(c.DataType)
+		}
+	}
+	if index < 0 {
+		return
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i][index], rows[j][index]
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return isLessThan(*a, *b, numeric)
+	})
+}
+
+// Returns true if the data type (as returned by the columns query) is a character string type
+func isTextDataType(dataType string) bool {
+	baseType := strings.TrimSpace(strings.SplitN(strings.ToLower(dataType), "(", 2)[0])
+	switch baseType {
+	case "text", "character varying", "character", "varchar", "char", "citext", "name":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package extractor
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
+)
+
+// Classifies the relations of every entity in `dataMap`, flags junction tables and adds each relation to the
+// `ReferencedBy` list of the entity it references.
+func analyseRelations(dataMap map[string]map[string]model.Entity) {
+	for schemaName, entityMap := range dataMap {
+		for entityName, entity := range entityMap {
+			for i := range entity.Relations {
+				classifyRelation(entity, &entity.Relations[i])
+			}
+			entity.IsJunctionTable = isJunctionTable(entity)
+			dataMap[schemaName][entityName] = entity
+		}
+	}
+
+	// Incoming references are added once all the relations are classified so they carry the same information
+	for _, entityMap := range dataMap {
+		for _, entity := range entityMap {
+			for _, r := range entity.Relations {
+				foreignSchema := strings.ToLower(r.ForeignEntitySchema)
+				foreignEntity := strings.ToLower(r.ForeignEntityName)
+				// Only entities in the described schemas can be updated
+				target, targetExists := dataMap[foreignSchema][foreignEntity]
+				if targetExists {
+					target.ReferencedBy = append(target.ReferencedBy, r)
+					dataMap[foreignSchema][foreignEntity] = target
+				}
+			}
+		}
+	}
+
+	// Keep a stable order, as the map iteration order is random
+	for _, entityMap := range dataMap {
+		for _, entity := range entityMap {
+			sort.Slice(entity.ReferencedBy, func(i, j int) bool {
+				a, b := entity.ReferencedBy[i], entity.ReferencedBy[j]
+				if a.SchemaName != b.SchemaName {
+					return a.SchemaName < b.SchemaName
+				}
+				if a.EntityName != b.EntityName {
+					return a.EntityName < b.EntityName
+				}
+				return a.RelationName < b.RelationName
+			})
+		}
+	}
+}
+
+// Sets the cardinality of a relation of `entity` and whether it is optional
+func classifyRelation(entity model.Entity, relation *model.Relation) {
+	columnNames := getRelationColumnNames(*relation)
+
+	relation.Cardinality = model.OneToMany
+	for _, uniqueKey := range entity.UniqueKeys {
+		// If the fk columns contain a unique key, each referenced row can be referenced at most once
+		if containsAllNames(columnNames, uniqueKey.ColumnNames) {
+			relation.Cardinality = model.OneToOne
+			break
+		}
+	}
+
+	relation.IsOptional = false
+	for _, column := range entity.Columns {
+		if column.IsNullable && containsName(columnNames, column.Name) {
+			relation.IsOptional = true
+			break
+		}
+	}
+}
+
+/*
+Returns true if the entity is a pure junction table of a many-to-many relationship.
+
+A junction table is a table with exactly 2 fks whose columns together are a unique key of the table, and that has no
+other columns.
+*/
+func isJunctionTable(entity model.Entity) bool {
+	if (entity.EntityType != model.Table && entity.EntityType != model.PartitionedTable) || len(entity.Relations) != 2 {
+		return false
+	}
+
+	fkColumnNames := append(getRelationColumnNames(entity.Relations[0]), getRelationColumnNames(entity.Relations[1])...)
+	for _, column := range entity.Columns {
+		if !containsName(fkColumnNames, column.Name) {
+			return false
+		}
+	}
+
+	for _, uniqueKey := range entity.UniqueKeys {
+		if containsAllNames(uniqueKey.ColumnNames, fkColumnNames) && containsAllNames(fkColumnNames, uniqueKey.ColumnNames) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the names of the columns in the referencing entity that are part of a relation
+func getRelationColumnNames(relation model.Relation) []string {
+	columnNames := make([]string, 0, len(relation.ColumnPairs))
+	for _, pair := range relation.ColumnPairs {
+		columnNames = append(columnNames, pair.ColumnName)
+	}
+	return columnNames
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if every name in `subset` is in `names`
+func containsAllNames(names []string, subset []string) bool {
+	for _, name := range subset {
+		if !containsName(names, name) {
+			return false
+		}
+	}
+	return true
+}
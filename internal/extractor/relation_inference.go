@@ -0,0 +1,254 @@
+package extractor
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/PDCMFinder/db-descriptor/pkg/connector"
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
+)
+
+// Confidence given by each piece of evidence of an inferred relation. They are combined as 1 - (1 - c1) * (1 - c2)...
+const (
+	// The column is named after the referenced entity and its key, like patient_id for patient.id
+	entityKeyNameConfidence = 0.5
+	// The column has the same name as the key of the referenced entity, like model_id for model.model_id
+	keyNameConfidence = 0.4
+	// The referenced column is the primary key or a unique key of its entity
+	primaryKeyConfidence = 0.3
+	uniqueKeyConfidence  = 0.2
+	// Both columns have the same data type, or types of the same family (integers, texts...)
+	sameTypeConfidence       = 0.2
+	compatibleTypeConfidence = 0.1
+	// All the sampled values are in the referenced column, or almost all of them
+	allValuesIncludedConfidence  = 0.6
+	mostValuesIncludedConfidence = 0.3
+)
+
+// Minimum fraction of the sampled values that must be in the referenced column for an inferred relation to be kept
+const minIncludedValuesFraction = 0.95
+
+// The column of an entity that identifies its rows and can be referenced by other entities
+type referenceKey struct {
+	entity     model.Entity
+	columnName string
+	// Confidence given by the kind of key, 0 for a column that is only named id
+	confidence float64
+	evidence   string
+}
+
+/*
+Adds to the tables, partitioned tables and materialized views in `dataMap` the relations that are not declared but can
+be inferred from their columns.
+
+A column that is not part of a declared fk references a column of another entity when its name is the name of that
+entity followed by the name of its key (patient_id references patient.id, also when the entity is named patients) or
+the same name as its key when the key is not just id. The key is the single column primary key, a single column unique
+key or a column named id. Both columns must have compatible data types. Entities in the same schema are preferred.
+When `input.InferRelationsSamples` is positive, that number of distinct values of the column must also be found in the
+referenced column. Only the most likely relation of each column is kept.
+*/
+func populateInferredRelations(
+	dataMap map[string]map[string]model.Entity, dBConnector connector.DBConnector, input connector.Input, db *sql.DB) {
+	entities := getEntitiesWithStorage(dataMap, false)
+	keys := make([]referenceKey, 0)
+	for _, e := range entities {
+		if key, hasKey := getReferenceKey(dataMap[e.SchemaName][e.EntityName]); hasKey {
+			keys = append(keys, key)
+		}
+	}
+
+	inferredCount := 0
+	for _, e := range entities {
+		entity := dataMap[e.SchemaName][e.EntityName]
+		declaredColumnNames := make([]string, 0)
+		for _, r := range entity.Relations {
+			declaredColumnNames = append(declaredColumnNames, getRelationColumnNames(r)...)
+		}
+
+		for _, column := range entity.Columns {
+			if containsName(declaredColumnNames, column.Name) {
+				continue
+			}
+			candidates := getInferredRelationCandidates(entity, column, keys)
+			var best *model.Relation
+			for i := range candidates {
+				if input.InferRelationsSamples > 0 &&
+					!checkValueInclusion(&candidates[i], dataMap, dBConnector, input, db) {
+					continue
+				}
+				if best == nil || candidates[i].Confidence > best.Confidence {
+					best = &candidates[i]
+				}
+			}
+			if best != nil {
+				entity.Relations = append(entity.Relations, *best)
+				inferredCount++
+			}
+		}
+		dataMap[e.SchemaName][e.EntityName] = entity
+	}
+	log.Println("Inferred", inferredCount, "relations")
+}
+
+// Returns the key of an entity that other entities can reference, if it has one
+func getReferenceKey(entity model.Entity) (referenceKey, bool) {
+	var uniqueKey *referenceKey
+	for _, k := range entity.UniqueKeys {
+		if len(k.ColumnNames) != 1 {
+			continue
+		}
+		if k.IsPrimaryKey {
+			return referenceKey{entity, k.ColumnNames[0], primaryKeyConfidence, "references the primary key"}, true
+		}
+		if uniqueKey == nil {
+			uniqueKey = &referenceKey{entity, k.ColumnNames[0], uniqueKeyConfidence, "references a unique key"}
+		}
+	}
+	if uniqueKey != nil {
+		return *uniqueKey, true
+	}
+	for _, c := range entity.Columns {
+		if c.Name == "id" {
+			return referenceKey{entity, c.Name, 0, ""}, true
+		}
+	}
+	return referenceKey{}, false
+}
+
+// Returns the relations of `column` with other entities that its name and type suggest, in the order of `keys`. If
+// there are any with entities of its own schema, only those are returned
+func getInferredRelationCandidates(entity model.Entity, column model.Column, keys []referenceKey) []model.Relation {
+	sameSchemaCandidates := make([]model.Relation, 0)
+	otherSchemaCandidates := make([]model.Relation, 0)
+	for _, key := range keys {
+		if key.entity.SchemaName == entity.SchemaName && key.entity.Name == entity.Name {
+			continue
+		}
+
+		relation := model.Relation{
+			SchemaName:          entity.SchemaName,
+			EntityName:          entity.Name,
+			RelationName:        entity.Name + "_" + column.Name + "_inferred",
+			ColumnPairs:         []model.ColumnPair{{ColumnName: column.Name, ForeignColumnName: key.columnName}},
+			ForeignEntitySchema: key.entity.SchemaName,
+			ForeignEntityName:   key.entity.Name,
+			IsInferred:          true}
+		addEvidence := func(confidence float64, evidence string) {
+			relation.Confidence = 1 - (1-relation.Confidence)*(1-confidence)
+			relation.Evidence = append(relation.Evidence, evidence)
+		}
+
+		keyName := key.entity.Name + "." + key.columnName
+		suffix := "_" + key.columnName
+		if strings.HasSuffix(column.Name, suffix) &&
+			isNameOfEntity(strings.TrimSuffix(column.Name, suffix), key.entity.Name) {
+			addEvidence(entityKeyNameConfidence, "name "+column.Name+" matches "+keyName)
+		} else if key.columnName != "id" && column.Name == key.columnName {
+			addEvidence(keyNameConfidence, "same name as "+keyName)
+		} else {
+			continue
+		}
+
+		var keyColumn model.Column
+		for _, c := range key.entity.Columns {
+			if c.Name == key.columnName {
+				keyColumn = c
+			}
+		}
+		if strings.EqualFold(column.DataType, keyColumn.DataType) {
+			addEvidence(sameTypeConfidence, "same data type "+column.DataType)
+		} else if getDataTypeFamily(column.DataType) == getDataTypeFamily(keyColumn.DataType) {
+			addEvidence(compatibleTypeConfidence, "compatible data types "+column.DataType+" and "+keyColumn.DataType)
+		} else {
+			continue
+		}
+		if key.confidence > 0 {
+			addEvidence(key.confidence, key.evidence)
+		}
+
+		if key.entity.SchemaName == entity.SchemaName {
+			sameSchemaCandidates = append(sameSchemaCandidates, relation)
+		} else {
+			otherSchemaCandidates = append(otherSchemaCandidates, relation)
+		}
+	}
+
+	if len(sameSchemaCandidates) > 0 {
+		return sameSchemaCandidates
+	}
+	return otherSchemaCandidates
+}
+
+// Returns true if `name` is the name of the entity in singular or plural, like patient for patient or patients
+func isNameOfEntity(name string, entityName string) bool {
+	if name == "" {
+		return false
+	}
+	if name == entityName || name+"s" == entityName || name+"es" == entityName {
+		return true
+	}
+	return strings.HasSuffix(name, "y") && strings.TrimSuffix(name, "y")+"ies" == entityName
+}
+
+// Returns the family of a data type (as returned by the columns query): integer, text, decimal or the base type
+func getDataTypeFamily(dataType string) string {
+	baseType := strings.TrimSpace(strings.SplitN(strings.ToLower(dataType), "(", 2)[0])
+	switch {
+	case baseType == "smallint" || baseType == "integer" || baseType == "bigint":
+		return "integer"
+	case baseType == "numeric" || baseType == "decimal":
+		return "decimal"
+	case isTextDataType(dataType):
+		return "text"
+	}
+	return baseType
+}
+
+// Checks that the sampled values of the column of an inferred relation are in the referenced column, adding the
+// result as evidence. Returns false if too many values are missing or the check cannot be done
+func checkValueInclusion(
+	relation *model.Relation,
+	dataMap map[string]map[string]model.Entity,
+	dBConnector connector.DBConnector,
+	input connector.Input,
+	db *sql.DB) bool {
+	pair := relation.ColumnPairs[0]
+	entity := dataMap[relation.SchemaName][relation.EntityName]
+	foreignEntity := dataMap[relation.ForeignEntitySchema][relation.ForeignEntityName]
+	schemaName, entityName := getEntityQueryNames(entity)
+	foreignSchemaName, foreignEntityName := getEntityQueryNames(foreignEntity)
+	queryStatement := dBConnector.GetValueInclusionQueryStatement(
+		schemaName,
+		entityName,
+		getColumnQueryNames(entity, []string{pair.ColumnName})[0],
+		foreignSchemaName,
+		foreignEntityName,
+		getColumnQueryNames(foreignEntity, []string{pair.ForeignColumnName})[0],
+		input.InferRelationsSamples)
+	var checked_count, missing_count int64
+	if err := db.QueryRow(queryStatement).Scan(&checked_count, &missing_count); err != nil {
+		log.Printf("Could not check the values of %s.%s.%s. Error: %s", relation.SchemaName, relation.EntityName,
+			pair.ColumnName, err.Error())
+		return false
+	}
+	if checked_count == 0 {
+		return true
+	}
+
+	includedFraction := float64(checked_count-missing_count) / float64(checked_count)
+	if includedFraction < minIncludedValuesFraction {
+		return false
+	}
+	confidence := allValuesIncludedConfidence
+	if missing_count > 0 {
+		confidence = mostValuesIncludedConfidence
+	}
+	relation.Confidence = 1 - (1-relation.Confidence)*(1-confidence)
+	relation.Evidence = append(relation.Evidence,
+		fmt.Sprintf("%d of %d sampled values found in %s.%s", checked_count-missing_count, checked_count,
+			relation.ForeignEntityName, pair.ForeignColumnName))
+	return true
+}
@@ -0,0 +1,43 @@
+package extractor
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/PDCMFinder/db-descriptor/pkg/connector"
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
+)
+
+// Populates the entities in `dataMap`, except partitions, with `input.SampleRows` example rows. Values are not masked
+// here, so masking must be applied afterwards
+func populateSampleRows(
+	dataMap map[string]map[string]model.Entity, dBConnector connector.DBConnector, input connector.Input, db *sql.DB) {
+	for schemaName, entityMap := range dataMap {
+		for entityName, entity := range entityMap {
+			if entity.EntityType == model.Partition || len(entity.Columns) == 0 {
+				continue
+			}
+
+			columnNames := make([]string, 0, len(entity.Columns))
+			for _, c := range entity.Columns {
+				columnNames = append(columnNames, c.Name)
+			}
+			// One more row than requested is read to know if the entity has more
+			querySchemaName, queryEntityName := getEntityQueryNames(entity)
+			queryStatement := dBConnector.GetSampleRowsQueryStatement(
+				querySchemaName, queryEntityName, getColumnQueryNames(entity, columnNames), 0, input.SampleRows+1)
+			rows, err := getSampleRowsList(queryStatement, len(columnNames), db)
+			if err != nil {
+				log.Printf("Could not read example rows of %s.%s. Error: %s", schemaName, entityName, err.Error())
+				continue
+			}
+
+			isTruncated := len(rows) > input.SampleRows
+			if isTruncated {
+				rows = rows[:input.SampleRows]
+			}
+			entity.SampleRows = &model.RowSet{ColumnNames: columnNames, Rows: rows, IsTruncated: isTruncated}
+			dataMap[schemaName][entityName] = entity
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package extractor
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Words that can follow the first word of a type name in a cast, like in `::character varying`
+var multiWordTypeParts = map[string]bool{
+	"varying": true, "precision": true, "with": true, "without": true, "time": true, "zone": true,
+}
+
+type sqlTokenKind int
+
+const (
+	identifierToken sqlTokenKind = iota
+	stringToken
+	numberToken
+	symbolToken
+)
+
+/*
+A token of a SQL text.
+
+`start` and `end` are the offsets (in runes) of the token in the original text, so the text of an expression can be
+recovered from its tokens. Quoted identifiers have `quoted` set and their text without the quotes.
+*/
+type sqlToken struct {
+	kind   sqlTokenKind
+	text   string
+	quoted bool
+	start  int
+	end    int
+}
+
+// Splits a SQL text into identifiers, literals and symbols. Comments are skipped. Escape strings (E'...') and
+// dollar-quoted strings ($$...$$, $tag$...$tag$) are strings. Returns false if the text has an unterminated comment,
+// literal or quoted identifier
+func tokenizeSQL(text string) ([]sqlToken, bool) {
+	tokens := make([]sqlToken, 0)
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			end := strings.Index(string(runes[i+2:]), "*/")
+			if end < 0 {
+				return nil, false
+			}
+			i += 2 + len([]rune(string(runes[i+2:])[:end])) + 2
+		case (r == 'e' || r == 'E') && i+1 < len(runes) && runes[i+1] == '\'':
+			// In escape strings a backslash escapes the next character
+			var sb strings.Builder
+			closed := false
+			j := i + 2
+			for ; j < len(runes); j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				} else if runes[j] == '\'' {
+					if j+1 >= len(runes) || runes[j+1] != '\'' {
+						closed = true
+						break
+					}
+					j++
+				}
+				sb.WriteRune(runes[j])
+			}
+			if !closed {
+				return nil, false
+			}
+			tokens = append(tokens, sqlToken{kind: stringToken, text: sb.String(), start: i, end: j + 1})
+			i = j + 1
+		case r == '$' && getDollarQuoteTag(runes, i) != "":
+			tag := getDollarQuoteTag(runes, i)
+			contentStart := i + len([]rune(tag))
+			end := strings.Index(string(runes[contentStart:]), tag)
+			if end < 0 {
+				return nil, false
+			}
+			content := []rune(string(runes[contentStart:])[:end])
+			tokens = append(tokens, sqlToken{
+				kind: stringToken, text: string(content), start: i, end: contentStart + len(content) + len([]rune(tag))})
+			i = contentStart + len(content) + len([]rune(tag))
+		case r == '\'' || r == '"':
+			var sb strings.Builder
+			closed := false
+			j := i + 1
+			for j < len(runes) {
+				if runes[j] == r {
+					// A doubled quote is an escaped quote
+					if j+1 < len(runes) && runes[j+1] == r {
+						sb.WriteRune(r)
+						j += 2
+						continue
+					}
+					closed = true
+					break
+				}
+				sb.WriteRune(runes[j])
+				j++
+			}
+			if !closed {
+				return nil, false
+			}
+			if r == '\'' {
+				tokens = append(tokens, sqlToken{kind: stringToken, text: sb.String(), start: i, end: j + 1})
+			} else {
+				tokens = append(tokens, sqlToken{kind: identifierToken, text: sb.String(), quoted: true, start: i, end: j + 1})
+			}
+			i = j + 1
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_' || runes[j] == '$') {
+				j++
+			}
+			tokens = append(tokens, sqlToken{kind: identifierToken, text: string(runes[i:j]), start: i, end: j})
+			i = j
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.' || runes[j] == 'e' || runes[j] == 'E') {
+				j++
+			}
+			tokens = append(tokens, sqlToken{kind: numberToken, text: string(runes[i:j]), start: i, end: j})
+			i = j
+		default:
+			if i+1 < len(runes) {
+				pair := string(runes[i : i+2])
+				if pair == "::" || pair == ">=" || pair == "<=" || pair == "<>" || pair == "!=" || pair == "||" {
+					tokens = append(tokens, sqlToken{kind: symbolToken, text: pair, start: i, end: i + 2})
+					i += 2
+					continue
+				}
+			}
+			tokens = append(tokens, sqlToken{kind: symbolToken, text: string(r), start: i, end: i + 1})
+			i++
+		}
+	}
+	return tokens, true
+}
+
+// Returns the tag that opens a dollar-quoted string at position `start`, like $$ or $body$, or an empty string if there
+// is none there. Parameters like $1 are not tags
+func getDollarQuoteTag(runes []rune, start int) string {
+	for j := start + 1; j < len(runes); j++ {
+		r := runes[j]
+		switch {
+		case r == '$':
+			return string(runes[start : j+1])
+		case unicode.IsLetter(r) || r == '_' || (unicode.IsDigit(r) && j > start+1):
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// Removes type casts (`::text`, `::character varying(10)[]`) as they do not change the values being compared
+func stripCasts(tokens []sqlToken) []sqlToken {
+	result := make([]sqlToken, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i].kind != symbolToken || tokens[i].text != "::" {
+			result = append(result, tokens[i])
+			continue
+		}
+		i++
+		// Type name, optionally schema qualified
+		if i < len(tokens) && tokens[i].kind == identifierToken {
+			for i+2 < len(tokens) && tokens[i+1].text == "." && tokens[i+2].kind == identifierToken {
+				i += 2
+			}
+			for i+1 < len(tokens) && tokens[i+1].kind == identifierToken && multiWordTypeParts[strings.ToLower(tokens[i+1].text)] {
+				i++
+			}
+		}
+		// Type modifiers
+		if i+1 < len(tokens) && tokens[i+1].text == "(" {
+			j := i + 2
+			for j < len(tokens) && (tokens[j].kind == numberToken || tokens[j].text == ",") {
+				j++
+			}
+			if j < len(tokens) && tokens[j].text == ")" {
+				i = j
+			}
+		}
+		// Array type
+		if i+2 < len(tokens) && tokens[i+1].text == "[" && tokens[i+2].text == "]" {
+			i += 2
+		}
+	}
+	return result
+}
+
+// Returns true if the token is the given keyword. Quoted identifiers are never keywords
+func isKeyword(token sqlToken, keyword string) bool {
+	return token.kind == identifierToken && !token.quoted && strings.EqualFold(token.text, keyword)
+}
+
+// Returns true if the token is the given symbol
+func isSymbol(token sqlToken, symbol string) bool {
+	return token.kind == symbolToken && token.text == symbol
+}
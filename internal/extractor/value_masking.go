@@ -0,0 +1,346 @@
+package extractor
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"log"
+	mathrand "math/rand"
+	"path"
+	"strings"
+	"unicode"
+
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
+)
+
+// Strategies to mask the values of a column
+const (
+	// Replaces each value with a hash of it, so equal values are still equal
+	hashMasking = "hash"
+	// Replaces each value with the same text
+	redactMasking = "redact"
+	// Replaces each value with a made up value with the same format (digits, letters and punctuation)
+	fakeMasking = "fake"
+)
+
+// Text that replaces the values masked with the redact strategy
+const redactedValue = "[REDACTED]"
+
+// Prefix of the patterns of masking rules that select columns by their classifications instead of their names
+const classificationSelectorPrefix = "classification:"
+
+// A rule to mask the values of the columns matching a pattern (see matchesColumnPattern), or with a classification or
+// category matching a glob pattern after the `classification:` prefix
+type maskingRule struct {
+	pattern  string
+	strategy string
+}
+
+/*
+Masks values according to a list of rules. The first rule matching a column gives the strategy for its values.
+
+Hashes are salted with a random value generated for each run, so short values like dates cannot be recovered by
+hashing all the possible values. A value is always masked to the same result within a run, with any strategy.
+*/
+type valueMasker struct {
+	rules []maskingRule
+	salt  []byte
+}
+
+// Returns a [valueMasker] with the rules in `rules`, each with the format pattern=strategy. Invalid rules stop the
+// program, as ignoring them could expose the values they were meant to mask
+func newValueMasker(rules []string) valueMasker {
+	masker := valueMasker{salt: make([]byte, 16)}
+	if _, err := rand.Read(masker.salt); err != nil {
+		log.Fatal("Could not generate the salt to mask values. Error: ", err)
+	}
+	for _, rule := range rules {
+		separator := strings.LastIndex(rule, "=")
+		if separator <= 0 {
+			log.Fatalf("Invalid masking rule [%s]. Expected format: pattern=strategy", rule)
+		}
+		strategy := strings.ToLower(strings.TrimSpace(rule[separator+1:]))
+		if strategy != hashMasking && strategy != redactMasking && strategy != fakeMasking {
+			log.Fatalf("Invalid masking strategy [%s] in rule [%s]. Expected hash, redact or fake", strategy, rule)
+		}
+		masker.rules = append(masker.rules, maskingRule{pattern: strings.TrimSpace(rule[:separator]), strategy: strategy})
+	}
+	return masker
+}
+
+// Returns the strategy to mask the values of a column, or an empty string if they are not masked
+func (m valueMasker) getStrategy(schemaName string, entityName string, column model.Column) string {
+	for _, rule := range m.rules {
+		if strings.HasPrefix(strings.ToLower(rule.pattern), classificationSelectorPrefix) {
+			if matchesClassification(rule.pattern[len(classificationSelectorPrefix):], column.Classifications) {
+				return rule.strategy
+			}
+		} else if matchesColumnPattern([]string{rule.pattern}, schemaName, entityName, column.Name) {
+			return rule.strategy
+		}
+	}
+	return ""
+}
+
+// Returns true if the name or the category of any of the classifications matches a glob pattern, case insensitive
+func matchesClassification(pattern string, classifications []model.Classification) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	for _, c := range classifications {
+		for _, name := range []string{c.Name, c.Category} {
+			if matched, err := path.Match(pattern, strings.ToLower(name)); err == nil && matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returns the value masked with `strategy`
+func (m valueMasker) mask(value string, strategy string) string {
+	switch strategy {
+	case redactMasking:
+		return redactedValue
+	case hashMasking:
+		sum := m.hash(value)
+		return hex.EncodeToString(sum[:8])
+	case fakeMasking:
+		sum := m.hash(value)
+		random := mathrand.New(mathrand.NewSource(int64(binary.BigEndian.Uint64(sum[:8]))))
+		var sb strings.Builder
+		for _, r := range value {
+			switch {
+			case unicode.IsDigit(r):
+				sb.WriteRune(rune('0' + random.Intn(10)))
+			case unicode.IsUpper(r):
+				sb.WriteRune(rune('A' + random.Intn(26)))
+			case unicode.IsLetter(r):
+				sb.WriteRune(rune('a' + random.Intn(26)))
+			default:
+				sb.WriteRune(r)
+			}
+		}
+		return sb.String()
+	}
+	return value
+}
+
+func (m valueMasker) hash(value string) [sha256.Size]byte {
+	return sha256.Sum256(append(append([]byte{}, m.salt...), value...))
+}
+
+// A column of an entity, to index the masking strategies of the columns of a description
+type maskedColumnKey struct {
+	schemaName string
+	entityName string
+	columnName string
+}
+
+// Masks the values of the columns of the entities in `dataMap` that match the rules of `masker`, wherever they appear
+// in the description: statistics, profiles, lists of values, reference data and sample rows. Columns of views are also
+// masked when they derive from masked columns (see getMaskingStrategies), and the codes and labels copied from
+// reference tables like the code and label columns they come from
+func maskDescriptionValues(dataMap map[string]map[string]model.Entity, masker valueMasker) {
+	strategies := getMaskingStrategies(dataMap, masker)
+	for schemaName, entityMap := range dataMap {
+		for entityName, entity := range entityMap {
+			for i := range entity.Columns {
+				column := &entity.Columns[i]
+				strategy := strategies[maskedColumnKey{schemaName, entityName, column.Name}]
+				codeStrategy, labelStrategy := getReferenceMaskingStrategies(dataMap, entity, column.Name, strategies)
+				if codeStrategy == "" {
+					codeStrategy = strategy
+				}
+				if labelStrategy == "" {
+					labelStrategy = strategy
+				}
+				maskReferenceValues(column, codeStrategy, labelStrategy, masker)
+				if strategy == "" {
+					continue
+				}
+				column.MaskingStrategy = strategy
+				maskColumnValues(column, masker)
+				if entity.SampleRows != nil {
+					maskRowSetColumn(entity.SampleRows, column.Name, strategy, masker)
+				}
+				if entity.ReferenceData != nil {
+					maskRowSetColumn(&entity.ReferenceData.Rows, column.Name, strategy, masker)
+				}
+			}
+			dataMap[schemaName][entityName] = entity
+		}
+	}
+}
+
+/*
+Returns the strategy to mask each column of the entities in `dataMap`, or an empty string for the columns that are not
+masked.
+
+A column that matches no rule takes the strategy of the column with the same name in the parents of its entity, so the
+rules of a partitioned or inherited table also mask its partitions and children. Otherwise, it takes the strategy of the
+first masked column in its lineage, so a view cannot expose the values of a masked column under another name, like
+`email AS contact`, or through an expression. The lineage of views over views is followed to the tables. Columns of views and materialized views without lineage, whose origin is not
+known, are redacted when the view reads an entity with masked columns.
+*/
+func getMaskingStrategies(dataMap map[string]map[string]model.Entity, masker valueMasker) map[maskedColumnKey]string {
+	strategies := make(map[maskedColumnKey]string)
+	// Columns being resolved, to stop at cycles
+	resolving := make(map[maskedColumnKey]bool)
+	var resolve func(entity model.Entity, column model.Column) string
+	var readsMaskedEntity func(entity model.Entity) bool
+	resolve = func(entity model.Entity, column model.Column) string {
+		key := maskedColumnKey{entity.SchemaName, entity.Name, column.Name}
+		if strategy, isResolved := strategies[key]; isResolved {
+			return strategy
+		}
+		if resolving[key] {
+			return ""
+		}
+		resolving[key] = true
+
+		strategy := masker.getStrategy(entity.SchemaName, entity.Name, column)
+		for _, parent := range entity.Parents {
+			if strategy != "" {
+				break
+			}
+			parentEntity, found := dataMap[parent.SchemaName][parent.EntityName]
+			if !found {
+				continue
+			}
+			if parentColumn := findEntityColumn(parentEntity, column.Name); parentColumn != nil {
+				strategy = resolve(parentEntity, *parentColumn)
+			}
+		}
+		for _, source := range column.Lineage {
+			if strategy != "" {
+				break
+			}
+			sourceEntity, found := dataMap[source.SourceSchemaName][source.SourceEntityName]
+			if !found {
+				continue
+			}
+			if sourceColumn := findEntityColumn(sourceEntity, source.SourceColumnName); sourceColumn != nil {
+				strategy = resolve(sourceEntity, *sourceColumn)
+			}
+		}
+		isView := entity.EntityType == model.View || entity.EntityType == model.MaterializedView
+		if strategy == "" && isView && len(column.Lineage) == 0 && readsMaskedEntity(entity) {
+			strategy = redactMasking
+		}
+		strategies[key] = strategy
+		return strategy
+	}
+	readsMaskedEntity = func(entity model.Entity) bool {
+		for _, dependency := range entity.Dependencies {
+			source, found := dataMap[dependency.SchemaName][dependency.EntityName]
+			if !found {
+				continue
+			}
+			for _, c := range source.Columns {
+				if resolve(source, c) != "" {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	for _, entityMap := range dataMap {
+		for _, entity := range entityMap {
+			for _, column := range entity.Columns {
+				resolve(entity, column)
+			}
+		}
+	}
+	return strategies
+}
+
+// Masks the values of a column kept in its statistics, profile and lists of values
+func maskColumnValues(column *model.Column, masker valueMasker) {
+	strategy := column.MaskingStrategy
+	maskFrequencies := func(values []model.ValueFrequency) {
+		for i := range values {
+			values[i].Value = masker.mask(values[i].Value, strategy)
+		}
+	}
+
+	if column.Stats != nil {
+		maskFrequencies(column.Stats.MostCommonValues)
+		for i := range column.Stats.HistogramBounds {
+			column.Stats.HistogramBounds[i] = masker.mask(column.Stats.HistogramBounds[i], strategy)
+		}
+	}
+	if column.Profile != nil {
+		if column.Profile.DistinctCount > 0 {
+			column.Profile.Min = masker.mask(column.Profile.Min, strategy)
+			column.Profile.Max = masker.mask(column.Profile.Max, strategy)
+		}
+		maskFrequencies(column.Profile.TopValues)
+	}
+	maskFrequencies(column.CategoricalValues)
+}
+
+/*
+Returns the strategies to mask the codes and the labels of the reference table that the column `columnName` of `entity`
+references (see applyReferenceValues). They are the strategies of the code and label columns of the reference table,
+or empty strings if those are not masked or the column does not reference a reference table.
+*/
+func getReferenceMaskingStrategies(dataMap map[string]map[string]model.Entity, entity model.Entity, columnName string,
+	strategies map[maskedColumnKey]string) (string, string) {
+	for _, r := range entity.Relations {
+		if len(r.ColumnPairs) != 1 || r.ColumnPairs[0].ColumnName != columnName {
+			continue
+		}
+		targetSchemaName, targetName := strings.ToLower(r.ForeignEntitySchema), strings.ToLower(r.ForeignEntityName)
+		target, targetExists := dataMap[targetSchemaName][targetName]
+		if !targetExists || target.ReferenceData == nil {
+			continue
+		}
+		codeColumnName := strings.ToLower(r.ColumnPairs[0].ForeignColumnName)
+		return strategies[maskedColumnKey{targetSchemaName, targetName, codeColumnName}],
+			strategies[maskedColumnKey{targetSchemaName, targetName, target.ReferenceData.LabelColumnName}]
+	}
+	return "", ""
+}
+
+// Masks the codes and labels of a reference table kept in a column that references it, and the allowed values of the
+// column when they are those codes
+func maskReferenceValues(column *model.Column, codeStrategy string, labelStrategy string, masker valueMasker) {
+	if codeStrategy == "" && labelStrategy == "" {
+		return
+	}
+	// The allowed values are the codes of the reference table, unless the column had a domain of its own
+	allowedValuesAreCodes := len(column.ReferenceValues) > 0 && len(column.AllowedValues) == len(column.ReferenceValues)
+	for i := range column.ReferenceValues {
+		if allowedValuesAreCodes && column.AllowedValues[i] != column.ReferenceValues[i].Code {
+			allowedValuesAreCodes = false
+		}
+	}
+	for i := range column.ReferenceValues {
+		column.ReferenceValues[i].Code = masker.mask(column.ReferenceValues[i].Code, codeStrategy)
+		if column.ReferenceValues[i].Label != "" {
+			column.ReferenceValues[i].Label = masker.mask(column.ReferenceValues[i].Label, labelStrategy)
+		}
+	}
+	if allowedValuesAreCodes {
+		column.AllowedValues = make([]string, 0, len(column.ReferenceValues))
+		for _, v := range column.ReferenceValues {
+			column.AllowedValues = append(column.AllowedValues, v.Code)
+		}
+	}
+}
+
+// Masks the non-null values of a column in a set of rows
+func maskRowSetColumn(rowSet *model.RowSet, columnName string, strategy string, masker valueMasker) {
+	for index, name := range rowSet.ColumnNames {
+		if name != columnName {
+			continue
+		}
+		for _, row := range rowSet.Rows {
+			if row[index] != nil {
+				masked := masker.mask(*row[index], strategy)
+				row[index] = &masked
+			}
+		}
+	}
+}
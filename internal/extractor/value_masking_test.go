@@ -0,0 +1,138 @@
+package extractor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
+)
+
+func TestMaskReferenceValues(t *testing.T) {
+	label := "Secret Hospital"
+	code := "A"
+	dataMap := map[string]map[string]model.Entity{"public": {
+		"provider_type": {SchemaName: "public", Name: "provider_type", EntityType: model.Table,
+			Columns: []model.Column{{Name: "code"}, {Name: "label"}},
+			ReferenceData: &model.ReferenceData{CodeColumnName: "code", LabelColumnName: "label",
+				Rows: model.RowSet{ColumnNames: []string{"code", "label"}, Rows: [][]*string{{&code, &label}}}}},
+		"patient": {SchemaName: "public", Name: "patient", EntityType: model.Table,
+			Columns: []model.Column{{Name: "provider_type", AllowedValues: []string{"A"},
+				ReferenceValues: []model.ReferenceValue{{Code: "A", Label: "Secret Hospital"}}}},
+			Relations: []model.Relation{{ForeignEntitySchema: "public", ForeignEntityName: "provider_type",
+				ColumnPairs: []model.ColumnPair{{ColumnName: "provider_type", ForeignColumnName: "code"}}}}},
+	}}
+
+	tests := []struct {
+		name                    string
+		rules                   []string
+		expectedReferenceValues []model.ReferenceValue
+		expectedAllowedValues   []string
+	}{
+		{
+			name:                    "codes and labels of the reference table",
+			rules:                   []string{"provider_type.code=redact", "provider_type.label=redact"},
+			expectedReferenceValues: []model.ReferenceValue{{Code: redactedValue, Label: redactedValue}},
+			expectedAllowedValues:   []string{redactedValue},
+		},
+		{
+			name:                    "labels only",
+			rules:                   []string{"provider_type.label=redact"},
+			expectedReferenceValues: []model.ReferenceValue{{Code: "A", Label: redactedValue}},
+			expectedAllowedValues:   []string{"A"},
+		},
+		{
+			name:                    "referencing column",
+			rules:                   []string{"patient.provider_type=redact"},
+			expectedReferenceValues: []model.ReferenceValue{{Code: redactedValue, Label: redactedValue}},
+			expectedAllowedValues:   []string{redactedValue},
+		},
+		{
+			name:                    "no rules",
+			expectedReferenceValues: []model.ReferenceValue{{Code: "A", Label: "Secret Hospital"}},
+			expectedAllowedValues:   []string{"A"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			masked := copyDataMap(dataMap)
+			maskDescriptionValues(masked, newValueMasker(test.rules))
+			column := masked["public"]["patient"].Columns[0]
+			if !reflect.DeepEqual(column.ReferenceValues, test.expectedReferenceValues) {
+				t.Errorf("ReferenceValues = %+v, expected %+v", column.ReferenceValues, test.expectedReferenceValues)
+			}
+			if !reflect.DeepEqual(column.AllowedValues, test.expectedAllowedValues) {
+				t.Errorf("AllowedValues = %v, expected %v", column.AllowedValues, test.expectedAllowedValues)
+			}
+		})
+	}
+}
+
+func TestMaskPartitionStats(t *testing.T) {
+	stats := func() *model.ColumnStats {
+		return &model.ColumnStats{
+			MostCommonValues: []model.ValueFrequency{{Value: "a@b.c", Frequency: 0.5}},
+			HistogramBounds:  []string{"a@a.a", "z@z.z"}}
+	}
+	dataMap := map[string]map[string]model.Entity{"public": {
+		"person": {SchemaName: "public", Name: "person", EntityType: model.PartitionedTable,
+			Columns:  []model.Column{{Name: "email", Stats: stats()}},
+			Children: []model.EntityReference{{SchemaName: "public", EntityName: "person_2020"}}},
+		"person_2020": {SchemaName: "public", Name: "person_2020", EntityType: model.Partition,
+			Columns:  []model.Column{{Name: "email", Stats: stats()}},
+			Parents:  []model.EntityReference{{SchemaName: "public", EntityName: "person"}},
+			Children: []model.EntityReference{{SchemaName: "public", EntityName: "person_2020_01"}}},
+		"person_2020_01": {SchemaName: "public", Name: "person_2020_01", EntityType: model.Partition,
+			Columns: []model.Column{{Name: "email", Stats: stats()}},
+			Parents: []model.EntityReference{{SchemaName: "public", EntityName: "person_2020"}}},
+	}}
+	expected := &model.ColumnStats{
+		MostCommonValues: []model.ValueFrequency{{Value: redactedValue, Frequency: 0.5}},
+		HistogramBounds:  []string{redactedValue, redactedValue}}
+
+	maskDescriptionValues(dataMap, newValueMasker([]string{"person.email=redact"}))
+	collapsePartitions(dataMap)
+
+	person := dataMap["public"]["person"]
+	if len(dataMap["public"]) != 1 || len(person.Partitions) != 1 || len(person.Partitions[0].Partitions) != 1 {
+		t.Fatalf("expected the partitions nested in person, got %+v", dataMap["public"])
+	}
+	for _, entity := range []model.Entity{person, person.Partitions[0], person.Partitions[0].Partitions[0]} {
+		column := entity.Columns[0]
+		if column.MaskingStrategy != redactMasking || !reflect.DeepEqual(column.Stats, expected) {
+			t.Errorf("%s.email: strategy %q and stats %+v, expected %q and %+v", entity.Name, column.MaskingStrategy,
+				column.Stats, redactMasking, expected)
+		}
+	}
+}
+
+// Returns a copy of `dataMap` whose columns, reference values and rows can be masked without changing the original
+func copyDataMap(dataMap map[string]map[string]model.Entity) map[string]map[string]model.Entity {
+	copied := make(map[string]map[string]model.Entity)
+	for schemaName, entityMap := range dataMap {
+		copied[schemaName] = make(map[string]model.Entity)
+		for entityName, entity := range entityMap {
+			entity.Columns = append([]model.Column{}, entity.Columns...)
+			for i := range entity.Columns {
+				column := &entity.Columns[i]
+				column.AllowedValues = append([]string(nil), column.AllowedValues...)
+				column.ReferenceValues = append([]model.ReferenceValue(nil), column.ReferenceValues...)
+				if column.Stats != nil {
+					stats := *column.Stats
+					stats.MostCommonValues = append([]model.ValueFrequency(nil), stats.MostCommonValues...)
+					stats.HistogramBounds = append([]string(nil), stats.HistogramBounds...)
+					column.Stats = &stats
+				}
+			}
+			if entity.ReferenceData != nil {
+				referenceData := *entity.ReferenceData
+				referenceData.Rows.Rows = make([][]*string, 0, len(entity.ReferenceData.Rows.Rows))
+				for _, row := range entity.ReferenceData.Rows.Rows {
+					referenceData.Rows.Rows = append(referenceData.Rows.Rows, append([]*string(nil), row...))
+				}
+				entity.ReferenceData = &referenceData
+			}
+			copied[schemaName][entityName] = entity
+		}
+	}
+	return copied
+}
@@ -0,0 +1,496 @@
+package extractor
+
+import (
+	"strings"
+
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
+)
+
+// A column of another entity read by a view, as reported by the database
+type viewColumnUsage struct {
+	viewSchema string
+	viewName   string
+	source     model.ColumnLineage
+}
+
+// An entity read in the FROM clause of a select, with the alias it is referenced by. Subqueries and function calls
+// have an alias but no entity. Items on the nullable side of an outer join have `isNullable` set. `position` is the
+// offset of the item in the query
+type fromItem struct {
+	schemaName string
+	entityName string
+	alias      string
+	isNullable bool
+	position   int
+}
+
+// A reference to a column inside an expression, like `p.name` or `name`, and its offset in the query
+type columnReference struct {
+	qualifier string
+	column    string
+	position  int
+}
+
+// An item of the target list of a select
+type selectTarget struct {
+	expression    string
+	references    []columnReference
+	isPassthrough bool
+}
+
+// A single select of a query. Queries with UNION, INTERSECT or EXCEPT have several
+type selectBranch struct {
+	targets   []selectTarget
+	fromItems []fromItem
+}
+
+// Words that end the target list or the FROM clause of a select
+var selectClauseKeywords = []string{"FROM", "WHERE", "GROUP", "HAVING", "ORDER", "LIMIT", "OFFSET", "WINDOW", "FETCH", "FOR", "INTO"}
+
+// Words that cannot be an alias of an item in the FROM clause
+var fromReservedKeywords = []string{
+	"ON", "USING", "JOIN", "LEFT", "RIGHT", "INNER", "FULL", "OUTER", "CROSS", "NATURAL", "LATERAL", "WHERE", "GROUP",
+	"HAVING", "ORDER", "LIMIT", "OFFSET", "WINDOW", "TABLESAMPLE", "UNION", "INTERSECT", "EXCEPT", "FETCH", "FOR",
+}
+
+// Words that start a join in a FROM clause
+var joinKeywords = []string{"JOIN", "LEFT", "RIGHT", "INNER", "FULL", "CROSS", "NATURAL"}
+
+// Words that can appear in an expression and are not column references
+var expressionKeywords = []string{
+	"CASE", "WHEN", "THEN", "ELSE", "END", "AND", "OR", "NOT", "NULL", "IS", "IN", "AS", "TRUE", "FALSE", "DISTINCT",
+	"BETWEEN", "LIKE", "ILIKE", "SIMILAR", "TO", "ANY", "ALL", "SOME", "EXISTS", "SELECT", "FROM", "WHERE", "ARRAY",
+	"INTERVAL", "CAST", "OVER", "PARTITION", "BY", "ORDER", "ASC", "DESC", "NULLS", "FIRST", "LAST", "FILTER", "WITHIN",
+	"GROUP", "ROW", "ROWS", "RANGE", "PRECEDING", "FOLLOWING", "CURRENT", "UNBOUNDED", "COLLATE", "ESCAPE",
+	"CURRENT_DATE", "CURRENT_TIME", "CURRENT_TIMESTAMP", "CURRENT_USER", "SESSION_USER", "LOCALTIME", "LOCALTIMESTAMP",
+}
+
+/*
+Computes the lineage of the columns of a view.
+
+The definition of the view is parsed to find, for each column of the view, the columns referenced by its expression in
+the target list. The references are resolved with the aliases in the FROM clause and checked against `usages`, the
+columns the database reports the view reads, so only real columns end up in the lineage. The result is indexed by the
+position of the column in the view.
+*/
+func buildViewColumnLineage(view model.Entity, usages []model.ColumnLineage) map[int][]model.ColumnLineage {
+	lineage := make(map[int][]model.ColumnLineage)
+	branches, ok := parseSelectQuery(view.ViewDefinition)
+	if !ok {
+		return lineage
+	}
+
+	for _, branch := range branches {
+		for i, target := range branch.targets {
+			if i >= len(view.Columns) {
+				break
+			}
+			for _, reference := range target.references {
+				source, found := resolveColumnReference(reference, branch.fromItems, usages)
+				if !found {
+					continue
+				}
+				source.IsPassthrough = target.isPassthrough
+				if !target.isPassthrough {
+					source.Expression = target.expression
+				}
+				if !containsLineage(lineage[i], source) {
+					lineage[i] = append(lineage[i], source)
+				}
+			}
+		}
+	}
+	return lineage
+}
+
+// Finds the column a reference points to among the columns read by the view
+func resolveColumnReference(
+	reference columnReference, fromItems []fromItem, usages []model.ColumnLineage) (model.ColumnLineage, bool) {
+
+	candidates := make([]fromItem, 0)
+	for _, item := range fromItems {
+		if reference.qualifier == "" {
+			candidates = append(candidates, item)
+		} else if strings.EqualFold(item.alias, reference.qualifier) ||
+			(item.alias == "" && strings.EqualFold(item.entityName, reference.qualifier)) {
+			candidates = append(candidates, item)
+		}
+	}
+
+	matches := make([]model.ColumnLineage, 0)
+	for _, usage := range usages {
+		if !strings.EqualFold(usage.SourceColumnName, reference.column) {
+			continue
+		}
+		for _, item := range candidates {
+			if strings.EqualFold(item.entityName, usage.SourceEntityName) &&
+				(item.schemaName == "" || strings.EqualFold(item.schemaName, usage.SourceSchemaName)) {
+				matches = append(matches, usage)
+				break
+			}
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], true
+	}
+
+	// The reference can point to a subquery or a CTE. Use the name of the column if it is not ambiguous
+	if len(matches) == 0 {
+		for _, usage := range usages {
+			if strings.EqualFold(usage.SourceColumnName, reference.column) {
+				matches = append(matches, usage)
+			}
+		}
+		if len(matches) == 1 {
+			return matches[0], true
+		}
+	}
+	return model.ColumnLineage{}, false
+}
+
+func containsLineage(lineage []model.ColumnLineage, source model.ColumnLineage) bool {
+	for _, l := range lineage {
+		if l.SourceSchemaName == source.SourceSchemaName && l.SourceEntityName == source.SourceEntityName &&
+			l.SourceColumnName == source.SourceColumnName {
+			return true
+		}
+	}
+	return false
+}
+
+// Parses a select query into its branches. Returns false if the query could not be understood
+func parseSelectQuery(query string) ([]selectBranch, bool) {
+	tokens, ok := tokenizeSQL(query)
+	if !ok || len(tokens) == 0 {
+		return nil, false
+	}
+	tokens = stripCasts(tokens)
+	runes := []rune(query)
+
+	start := 0
+	// Skip the common table expressions, the main select is the first one at the top level after them
+	if isKeyword(tokens[0], "WITH") {
+		depth := 0
+		for start = 1; start < len(tokens); start++ {
+			depth += parenthesisDelta(tokens[start])
+			if depth == 0 && isKeyword(tokens[start], "SELECT") {
+				break
+			}
+		}
+	}
+
+	branches := make([]selectBranch, 0)
+	depth := 0
+	for i := start; i <= len(tokens); i++ {
+		if i == len(tokens) || (depth == 0 && (isKeyword(tokens[i], "UNION") || isKeyword(tokens[i], "INTERSECT") ||
+			isKeyword(tokens[i], "EXCEPT"))) {
+			branch, ok := parseSelectBranch(tokens[start:i], runes)
+			if !ok {
+				return nil, false
+			}
+			branches = append(branches, branch)
+			if i+1 < len(tokens) && (isKeyword(tokens[i+1], "ALL") || isKeyword(tokens[i+1], "DISTINCT")) {
+				i++
+			}
+			start = i + 1
+			continue
+		}
+		depth += parenthesisDelta(tokens[i])
+	}
+	return branches, len(branches) > 0
+}
+
+func parseSelectBranch(tokens []sqlToken, runes []rune) (selectBranch, bool) {
+	tokens = trimStatement(tokens)
+	if len(tokens) == 0 || !isKeyword(tokens[0], "SELECT") {
+		return selectBranch{}, false
+	}
+
+	i := 1
+	if i < len(tokens) && isKeyword(tokens[i], "DISTINCT") {
+		i++
+		if i < len(tokens) && isKeyword(tokens[i], "ON") {
+			i = skipParenthesisGroup(tokens, i+1)
+		}
+	} else if i < len(tokens) && isKeyword(tokens[i], "ALL") {
+		i++
+	}
+
+	targetsEnd := findTopLevelKeyword(tokens, i, selectClauseKeywords)
+	branch := selectBranch{}
+	for _, item := range splitTopLevel(tokens[i:targetsEnd], ",") {
+		if len(item) > 0 {
+			branch.targets = append(branch.targets, parseSelectTarget(item, runes))
+		}
+	}
+
+	if targetsEnd < len(tokens) && isKeyword(tokens[targetsEnd], "FROM") {
+		fromEnd := findTopLevelKeyword(tokens, targetsEnd+1, selectClauseKeywords[1:])
+		branch.fromItems = parseFromClause(tokens[targetsEnd+1 : fromEnd])
+	}
+	return branch, true
+}
+
+// Removes a trailing semicolon and the parenthesis wrapping a whole select
+func trimStatement(tokens []sqlToken) []sqlToken {
+	for len(tokens) > 0 && isSymbol(tokens[len(tokens)-1], ";") {
+		tokens = tokens[:len(tokens)-1]
+	}
+	for len(tokens) >= 2 && isSymbol(tokens[0], "(") {
+		if end, closed := findParenthesisGroupEnd(tokens, 0); !closed || end != len(tokens) {
+			break
+		}
+		tokens = tokens[1 : len(tokens)-1]
+	}
+	return tokens
+}
+
+func parseSelectTarget(tokens []sqlToken, runes []rune) selectTarget {
+	expressionTokens := tokens
+	if len(tokens) >= 3 && isKeyword(tokens[len(tokens)-2], "AS") {
+		expressionTokens = tokens[:len(tokens)-2]
+	}
+
+	target := selectTarget{
+		expression: string(runes[expressionTokens[0].start:expressionTokens[len(expressionTokens)-1].end]),
+		references: collectColumnReferences(expressionTokens),
+	}
+
+	// A passthrough column is a plain reference, like `p.name`
+	target.isPassthrough = len(target.references) == 1
+	for _, t := range expressionTokens {
+		if t.kind != identifierToken && !isSymbol(t, ".") {
+			target.isPassthrough = false
+		}
+	}
+	return target
+}
+
+// Returns the column references in the tokens of an expression
+func collectColumnReferences(tokens []sqlToken) []columnReference {
+	references := make([]columnReference, 0)
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		if t.kind != identifierToken || (!t.quoted && containsKeyword(expressionKeywords, t.text)) {
+			// A type after AS, like in CAST(x AS integer), is not a column
+			if isKeyword(t, "AS") && i+1 < len(tokens) && tokens[i+1].kind == identifierToken {
+				i++
+				for i+1 < len(tokens) && tokens[i+1].kind == identifierToken && multiWordTypeParts[strings.ToLower(tokens[i+1].text)] {
+					i++
+				}
+			}
+			continue
+		}
+
+		parts := []string{t.text}
+		for i+2 < len(tokens) && isSymbol(tokens[i+1], ".") && tokens[i+2].kind == identifierToken {
+			parts = append(parts, tokens[i+2].text)
+			i += 2
+		}
+		if i+1 < len(tokens) && (isSymbol(tokens[i+1], "(") || isSymbol(tokens[i+1], ".")) {
+			// A function call or a star (`p.*`)
+			continue
+		}
+		if len(parts) == 1 && i+1 < len(tokens) && tokens[i+1].kind == stringToken {
+			// A typed literal, like date '2020-01-01'
+			continue
+		}
+
+		reference := columnReference{column: strings.ToLower(parts[len(parts)-1]), position: t.start}
+		if len(parts) > 1 {
+			reference.qualifier = strings.ToLower(parts[len(parts)-2])
+		}
+		references = append(references, reference)
+	}
+	return references
+}
+
+// Parses the FROM clause of a select into the list of entities it reads
+func parseFromClause(tokens []sqlToken) []fromItem {
+	items := make([]fromItem, 0)
+	expectItem := true
+	// Type of the outer join whose right item is expected (LEFT, RIGHT or FULL), empty for other joins
+	outerJoin := ""
+	// Marks the items added since position `start` as nullable if they are the right item of a LEFT or FULL join
+	endItem := func(start int) {
+		if outerJoin == "LEFT" || outerJoin == "FULL" {
+			for j := start; j < len(items); j++ {
+				items[j].isNullable = true
+			}
+		}
+		outerJoin = ""
+	}
+	for i := 0; i < len(tokens); {
+		t := tokens[i]
+		start := len(items)
+		switch {
+		case isKeyword(t, "LEFT") || isKeyword(t, "RIGHT") || isKeyword(t, "FULL"):
+			outerJoin = strings.ToUpper(t.text)
+			// The items before a RIGHT or FULL join are on its nullable side
+			if outerJoin != "LEFT" {
+				for j := range items {
+					items[j].isNullable = true
+				}
+			}
+			i++
+		case isSymbol(t, "("):
+			end, closed := findParenthesisGroupEnd(tokens, i)
+			if !closed {
+				// An unbalanced parenthesis, the rest of the clause cannot be understood
+				return items
+			}
+			inner := tokens[i+1 : end-1]
+			isSubquery := len(inner) > 0 &&
+				(isKeyword(inner[0], "SELECT") || isKeyword(inner[0], "WITH") || isKeyword(inner[0], "VALUES"))
+			if !isSubquery {
+				// A parenthesised join
+				items = append(items, parseFromClause(inner)...)
+			}
+			i = end
+			alias, next := parseFromAlias(tokens, i)
+			if isSubquery {
+				items = append(items, fromItem{alias: alias})
+			}
+			endItem(start)
+			i = next
+			expectItem = false
+		case isSymbol(t, ",") || isKeyword(t, "JOIN"):
+			expectItem = true
+			i++
+		case isKeyword(t, "ON") || isKeyword(t, "USING"):
+			// Skip the join condition
+			depth := 0
+			for i++; i < len(tokens); i++ {
+				if depth == 0 && (isSymbol(tokens[i], ",") || (!tokens[i].quoted && containsKeyword(joinKeywords, tokens[i].text))) {
+					break
+				}
+				depth += parenthesisDelta(tokens[i])
+			}
+			expectItem = false
+		case expectItem && isKeyword(t, "ROWS") && i+2 < len(tokens) && isKeyword(tokens[i+1], "FROM") &&
+			isSymbol(tokens[i+2], "("):
+			// ROWS FROM (...) joins the results of functions, it does not read an entity
+			end, closed := findParenthesisGroupEnd(tokens, i+2)
+			if !closed {
+				return items
+			}
+			item := fromItem{}
+			item.alias, i = parseFromAlias(tokens, end)
+			items = append(items, item)
+			endItem(start)
+			expectItem = false
+		case expectItem && t.kind == identifierToken && !(isKeyword(t, "ONLY") || isKeyword(t, "LATERAL")):
+			parts := []string{t.text}
+			for i+2 < len(tokens) && isSymbol(tokens[i+1], ".") && tokens[i+2].kind == identifierToken {
+				parts = append(parts, tokens[i+2].text)
+				i += 2
+			}
+			i++
+			item := fromItem{entityName: strings.ToLower(parts[len(parts)-1]), position: t.start}
+			if len(parts) > 1 {
+				item.schemaName = strings.ToLower(parts[len(parts)-2])
+			}
+			if i < len(tokens) && isSymbol(tokens[i], "(") {
+				// A function in the FROM clause does not read an entity
+				i = skipParenthesisGroup(tokens, i)
+				item = fromItem{}
+			}
+			item.alias, i = parseFromAlias(tokens, i)
+			items = append(items, item)
+			endItem(start)
+			expectItem = false
+		default:
+			i++
+		}
+	}
+	return items
+}
+
+// Reads the optional alias of an item of a FROM clause at position `i`. Returns the alias and the next position
+func parseFromAlias(tokens []sqlToken, i int) (string, int) {
+	// Functions can add the number of each row to their results
+	if i+1 < len(tokens) && isKeyword(tokens[i], "WITH") && isKeyword(tokens[i+1], "ORDINALITY") {
+		i += 2
+	}
+	if i < len(tokens) && isKeyword(tokens[i], "AS") {
+		i++
+	}
+	if i < len(tokens) && tokens[i].kind == identifierToken &&
+		(tokens[i].quoted || !containsKeyword(fromReservedKeywords, tokens[i].text)) {
+		alias := strings.ToLower(tokens[i].text)
+		i++
+		// Column aliases, like in `AS t(a, b)`
+		if i < len(tokens) && isSymbol(tokens[i], "(") {
+			i = skipParenthesisGroup(tokens, i)
+		}
+		return alias, i
+	}
+	return "", i
+}
+
+// Returns the position of the first keyword of the list found at the top level from position `start`, or the length
+// of the tokens if there is none
+func findTopLevelKeyword(tokens []sqlToken, start int, keywords []string) int {
+	depth := 0
+	for i := start; i < len(tokens); i++ {
+		if depth == 0 && !tokens[i].quoted && tokens[i].kind == identifierToken && containsKeyword(keywords, tokens[i].text) {
+			return i
+		}
+		depth += parenthesisDelta(tokens[i])
+	}
+	return len(tokens)
+}
+
+// Splits tokens by a separator found at the top level
+func splitTopLevel(tokens []sqlToken, separator string) [][]sqlToken {
+	parts := make([][]sqlToken, 0)
+	depth := 0
+	start := 0
+	for i, t := range tokens {
+		if depth == 0 && isSymbol(t, separator) {
+			parts = append(parts, tokens[start:i])
+			start = i + 1
+			continue
+		}
+		depth += parenthesisDelta(t)
+	}
+	return append(parts, tokens[start:])
+}
+
+// Returns the position after the parenthesis group (or bracket group) that opens at position `start`
+func skipParenthesisGroup(tokens []sqlToken, start int) int {
+	end, _ := findParenthesisGroupEnd(tokens, start)
+	return end
+}
+
+// Returns the position after the parenthesis group (or bracket group) that opens at position `start`, and false if the
+// group is not closed, in which case the position is the length of the tokens
+func findParenthesisGroupEnd(tokens []sqlToken, start int) (int, bool) {
+	depth := 0
+	for i := start; i < len(tokens); i++ {
+		depth += parenthesisDelta(tokens[i])
+		if depth == 0 {
+			return i + 1, true
+		}
+	}
+	return len(tokens), false
+}
+
+func parenthesisDelta(token sqlToken) int {
+	if isSymbol(token, "(") || isSymbol(token, "[") {
+		return 1
+	}
+	if isSymbol(token, ")") || isSymbol(token, "]") {
+		return -1
+	}
+	return 0
+}
+
+func containsKeyword(keywords []string, word string) bool {
+	for _, k := range keywords {
+		if strings.EqualFold(k, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package extractor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFromClause(t *testing.T) {
+	tests := []struct {
+		name     string
+		clause   string
+		expected []fromItem
+	}{
+		{
+			name:     "single entity",
+			clause:   "patient",
+			expected: []fromItem{{entityName: "patient", position: 0}},
+		},
+		{
+			name:   "schema and alias",
+			clause: "public.patient AS p, model m",
+			expected: []fromItem{
+				{schemaName: "public", entityName: "patient", alias: "p", position: 0},
+				{entityName: "model", alias: "m", position: 21},
+			},
+		},
+		{
+			name:   "outer joins",
+			clause: "patient p LEFT JOIN model m ON m.patient_id = p.id RIGHT JOIN sample s USING (id)",
+			expected: []fromItem{
+				{entityName: "patient", alias: "p", isNullable: true, position: 0},
+				{entityName: "model", alias: "m", isNullable: true, position: 20},
+				{entityName: "sample", alias: "s", position: 62},
+			},
+		},
+		{
+			name:   "subquery and function",
+			clause: "(SELECT id FROM patient) AS q, generate_series(1, 3) g",
+			expected: []fromItem{
+				{alias: "q"},
+				{alias: "g"},
+			},
+		},
+		{
+			name:   "values lists and functions",
+			clause: "(VALUES (1), (2)) AS t(id), ROWS FROM (generate_series(1, 2)) r, unnest(a) WITH ORDINALITY u(v, n)",
+			expected: []fromItem{
+				{alias: "t"},
+				{alias: "r"},
+				{alias: "u"},
+			},
+		},
+		{
+			name:   "parenthesised join",
+			clause: "(patient p JOIN model m ON m.patient_id = p.id)",
+			expected: []fromItem{
+				{entityName: "patient", alias: "p", position: 1},
+				{entityName: "model", alias: "m", position: 16},
+			},
+		},
+		{
+			name:     "unclosed subquery",
+			clause:   "patient p, (SELECT id FROM model",
+			expected: []fromItem{{entityName: "patient", alias: "p", position: 0}},
+		},
+		{
+			name:     "unclosed parenthesis",
+			clause:   "(",
+			expected: []fromItem{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokens, ok := tokenizeSQL(test.clause)
+			if !ok {
+				t.Fatalf("could not tokenize %q", test.clause)
+			}
+			items := parseFromClause(tokens)
+			if !reflect.DeepEqual(items, test.expected) {
+				t.Errorf("parseFromClause(%q) = %+v, expected %+v", test.clause, items, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseSelectQuery(t *testing.T) {
+	tests := []struct {
+		name                 string
+		query                string
+		ok                   bool
+		expectedBranches     int
+		expectedTargets      []string
+		expectedPassthroughs []bool
+	}{
+		{
+			name:                 "plain columns and expressions",
+			query:                "SELECT p.id, p.name AS patient_name, upper(p.name) AS upper_name FROM patient p",
+			ok:                   true,
+			expectedBranches:     1,
+			expectedTargets:      []string{"p.id", "p.name", "upper(p.name)"},
+			expectedPassthroughs: []bool{true, true, false},
+		},
+		{
+			name:                 "casts are not part of the expression",
+			query:                "SELECT p.id::text AS id FROM patient p;",
+			ok:                   true,
+			expectedBranches:     1,
+			expectedTargets:      []string{"p.id"},
+			expectedPassthroughs: []bool{true},
+		},
+		{
+			name:                 "common table expressions",
+			query:                "WITH q AS (SELECT id FROM patient) SELECT q.id FROM q",
+			ok:                   true,
+			expectedBranches:     1,
+			expectedTargets:      []string{"q.id"},
+			expectedPassthroughs: []bool{true},
+		},
+		{
+			name:                 "union",
+			query:                "SELECT id FROM patient UNION ALL SELECT id FROM model",
+			ok:                   true,
+			expectedBranches:     2,
+			expectedTargets:      []string{"id"},
+			expectedPassthroughs: []bool{true},
+		},
+		{
+			name:  "not a select",
+			query: "DELETE FROM patient",
+		},
+		{
+			name:  "unterminated string",
+			query: "SELECT 'abc FROM patient",
+		},
+		{
+			name:                 "unclosed subquery",
+			query:                "SELECT id FROM (SELECT id FROM patient",
+			ok:                   true,
+			expectedBranches:     1,
+			expectedTargets:      []string{"id"},
+			expectedPassthroughs: []bool{true},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			branches, ok := parseSelectQuery(test.query)
+			if ok != test.ok {
+				t.Fatalf("parseSelectQuery(%q) returned %v, expected %v", test.query, ok, test.ok)
+			}
+			if !ok {
+				return
+			}
+			if len(branches) != test.expectedBranches {
+				t.Fatalf("expected %d branches, got %d", test.expectedBranches, len(branches))
+			}
+			targets := branches[0].targets
+			if len(targets) != len(test.expectedTargets) {
+				t.Fatalf("expected %d targets, got %+v", len(test.expectedTargets), targets)
+			}
+			for i, target := range targets {
+				if target.expression != test.expectedTargets[i] {
+					t.Errorf("target %d: expected expression %q, got %q", i, test.expectedTargets[i], target.expression)
+				}
+				if target.isPassthrough != test.expectedPassthroughs[i] {
+					t.Errorf("target %d: expected passthrough %v, got %v", i, test.expectedPassthroughs[i],
+						target.isPassthrough)
+				}
+			}
+		})
+	}
+}
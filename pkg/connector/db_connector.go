@@ -19,8 +19,11 @@ type DBConnector interface {
 		A SQL query that brings the information for entities. Implementations are expected to provide the following columns:
 		- table_schema (Schema of the entity)
 		- table_name   (Entity name)
-		- table_type   (Entity type: view/table)
+		- table_type   (Entity type: VIEW, MATERIALIZED VIEW, BASE TABLE, FOREIGN TABLE, PARTITIONED TABLE, PARTITION)
 		- comment      (Entity comment)
+		- owner        (Role that owns the entity)
+		- is_row_security_enabled (Whether row-level security is enabled)
+		- is_row_security_forced  (Whether row-level security also applies to the owner)
 
 	*/
 	GetEntitiesQueryStatement() string
@@ -29,21 +32,368 @@ type DBConnector interface {
 		- table_schema (Schema of the entity)
 		- table_name   (Entity name)
 		- column_name  (The name of the column)
-		- data_type    (Data type of the column)
-		- comment      (Column comment)
+		- data_type      (Data type of the column)
+		- comment        (Column comment)
+		- is_primary_key (Whether the column is part of the pk)
+		- is_foreign_key (Whether the column is part of a fk)
+		- is_nullable    (Whether the column accepts nulls)
+		- type_schema    (Schema of the data type if it is a user-defined type. Empty otherwise)
+		- type_name      (Name of the data type if it is a user-defined type. Empty otherwise)
 
 	*/
 	GetColumnsQueryStatement() string
 	/*
-		A SQL query that brings relations between entities (FKs). Implementations are expected to provide the following columns:
+		A SQL query that brings relations between entities (FKs). Implementations are expected to provide one row per pair
+		of columns in the fk, ordered by table_schema, table_name, constraint_name and position, with the following columns:
 		- table_schema 			(Schema of the entity)
+		- constraint_name   	(The name of the fk)
 		- table_name   			(Entity name)
 		- column_name  			(The name of the column)
-		- constraint_name   	(The name of the fk)
 		- foreign_table_schema	(The name of the schema of the referenced table)
 		- foreign_table_name	(The name of the referenced table)
-		- foreign_column_name	(The name of the pk column in the referenced table)
+		- foreign_column_name	(The name of the referenced column in the referenced table)
+		- position				(Position of the pair of columns in the fk, starting at 1)
+		- on_delete				(Action on delete: NO ACTION, RESTRICT, CASCADE, SET NULL, SET DEFAULT)
+		- on_update				(Action on update: NO ACTION, RESTRICT, CASCADE, SET NULL, SET DEFAULT)
+		- match_type			(Match type of the fk: SIMPLE, FULL, PARTIAL)
+		- is_deferrable			(Whether the fk can be deferred)
+		- is_initially_deferred	(Whether the fk is deferred by default)
 
 	*/
 	GetRelationsQueryStatement() string
+	/*
+		A SQL query that brings the check constraints of the entities. Implementations are expected to provide the following columns:
+		- table_schema 		(Schema of the entity)
+		- table_name   		(Entity name)
+		- constraint_name	(The name of the check constraint)
+		- expression		(The definition of the constraint, like CHECK (col IN ('a', 'b')))
+		- column_names		(Comma separated list of the columns involved in the constraint)
+
+	*/
+	GetCheckConstraintsQueryStatement() string
+	/*
+		A SQL query that brings the unique keys (pk, unique constraints and unique indexes) of the entities. Implementations
+		are expected to provide the following columns:
+		- table_schema 		(Schema of the entity)
+		- table_name   		(Entity name)
+		- key_name			(The name of the constraint or index)
+		- column_names		(Comma separated list of the columns in the key, in order)
+		- is_primary_key	(Whether the key is the pk of the entity)
+
+	*/
+	GetUniqueKeysQueryStatement() string
+	/*
+		A SQL query that brings the definition of the views. Implementations are expected to provide the following columns:
+		- table_schema 		(Schema of the view)
+		- table_name   		(View name)
+		- view_definition	(The query of the view)
+
+	*/
+	GetViewDefinitionsQueryStatement() string
+	/*
+		A SQL query that brings the entities each view reads. Implementations are expected to provide the following columns:
+		- view_schema 	(Schema of the view)
+		- view_name   	(View name)
+		- view_type   	(Type of the view)
+		- table_schema 	(Schema of the entity the view reads)
+		- table_name   	(Name of the entity the view reads)
+		- table_type   	(Type of the entity the view reads, with the same values as in the entities query)
+
+	*/
+	GetViewDependenciesQueryStatement() string
+	/*
+		A SQL query that brings the columns of other entities each view reads. Implementations are expected to provide the
+		following columns:
+		- view_schema 	(Schema of the view)
+		- view_name   	(View name)
+		- table_schema 	(Schema of the entity the view reads)
+		- table_name   	(Name of the entity the view reads)
+		- column_name  	(Name of the column the view reads)
+
+	*/
+	GetViewColumnUsageQueryStatement() string
+	/*
+		A SQL query that brings the inheritance relationships between entities, including partitions. Implementations are
+		expected to provide the following columns:
+		- child_schema 		(Schema of the child entity)
+		- child_name   		(Name of the child entity)
+		- child_type   		(Type of the child entity, with the same values as in the entities query)
+		- parent_schema 	(Schema of the parent entity)
+		- parent_name   	(Name of the parent entity)
+		- parent_type   	(Type of the parent entity, with the same values as in the entities query)
+		- partition_bound	(Bound of the child if it is a partition, like FOR VALUES IN ('a'). Empty otherwise)
+
+	*/
+	GetInheritanceQueryStatement() string
+	/*
+		A SQL query that brings the partitioning of partitioned tables. Implementations are expected to provide the
+		following columns:
+		- table_schema 			(Schema of the partitioned table)
+		- table_name   			(Partitioned table name)
+		- partition_strategy	(Strategy: range, list, hash)
+		- partition_key			(The columns or expressions of the partition key)
+
+	*/
+	GetPartitionKeysQueryStatement() string
+	/*
+		A SQL query that brings the functions and procedures with their arguments. Implementations are expected to provide
+		one row per argument (or a single row with argument_position 0 for routines without arguments), ordered by
+		routine_schema, routine_signature and argument_position, with the following columns:
+		- routine_schema 		(Schema of the routine)
+		- routine_name   		(Routine name)
+		- routine_signature		(Name and argument types, identifying overloaded routines)
+		- routine_kind			(function, procedure, aggregate, window)
+		- return_type			(Return type of the routine. Empty for procedures)
+		- language				(Language the routine is written in)
+		- volatility			(immutable, stable, volatile)
+		- is_security_definer	(Whether the routine runs with the privileges of its owner)
+		- comment				(Routine comment)
+		- source				(Source code of the routine, if requested. Empty otherwise)
+		- argument_position		(Position of the argument, starting at 1)
+		- argument_name			(Argument name. Empty if it has no name)
+		- argument_type			(Data type of the argument)
+		- argument_mode			(IN, OUT, INOUT, VARIADIC, TABLE)
+
+	*/
+	GetRoutinesQueryStatement() string
+	/*
+		A SQL query that brings the triggers of the entities. Implementations are expected to provide the following columns:
+		- table_schema 		(Schema of the entity)
+		- table_name   		(Entity name)
+		- trigger_name		(The name of the trigger)
+		- timing			(BEFORE, AFTER, INSTEAD OF)
+		- events			(Comma separated list of the events that fire the trigger: INSERT, UPDATE, DELETE, TRUNCATE)
+		- level				(ROW, STATEMENT)
+		- function_schema	(Schema of the function the trigger executes)
+		- function_name		(Name of the function the trigger executes)
+		- enabled			(ENABLED, DISABLED, REPLICA, ALWAYS)
+		- condition			(WHEN condition of the trigger. Empty if there is none)
+		- comment			(Trigger comment)
+
+	*/
+	GetTriggersQueryStatement() string
+	/*
+		A SQL query that brings the event triggers of the database. Implementations are expected to provide the following
+		columns:
+		- trigger_name		(The name of the event trigger)
+		- event				(The event that fires the trigger)
+		- tags				(Comma separated list of the command tags the trigger is restricted to. Empty if any)
+		- function_schema	(Schema of the function the trigger executes)
+		- function_name		(Name of the function the trigger executes)
+		- enabled			(ENABLED, DISABLED, REPLICA, ALWAYS)
+		- comment			(Event trigger comment)
+
+	*/
+	GetEventTriggersQueryStatement() string
+	/*
+		A SQL query that brings the sequences. Implementations are expected to provide the following columns:
+		- sequence_schema	(Schema of the sequence)
+		- sequence_name		(Sequence name)
+		- data_type			(Data type of the sequence)
+		- start_value		(Start value)
+		- current_value		(Last value returned by the sequence. Null if it has not been used or cannot be read)
+		- increment			(Increment)
+		- min_value			(Minimum value)
+		- max_value			(Maximum value)
+		- cache_size		(Number of values preallocated)
+		- is_cycle			(Whether the sequence restarts when it reaches its limit)
+		- owner_schema		(Schema of the entity of the column that owns the sequence. Empty if none)
+		- owner_table		(Entity of the column that owns the sequence. Empty if none)
+		- owner_column		(Column that owns the sequence. Empty if none)
+		- is_identity		(Whether the sequence belongs to an identity column)
+
+	*/
+	GetSequencesQueryStatement() string
+	/*
+		A SQL query that brings the user-defined types (enums, domains and composite types). Implementations are expected
+		to provide the following columns:
+		- type_schema	(Schema of the type)
+		- type_name		(Type name)
+		- type_kind		(enum, domain, composite)
+		- base_type		(Data type a domain is based on. Empty for other kinds)
+		- is_not_null	(Whether a domain rejects nulls)
+		- default_value	(Default value of a domain. Empty if none)
+		- comment		(Type comment)
+
+	*/
+	GetUserDefinedTypesQueryStatement() string
+	/*
+		A SQL query that brings the members of the user-defined types: labels of enums, attributes of composite types and
+		constraints of domains. Implementations are expected to provide the following columns, ordered by type_schema,
+		type_name, member_kind and position:
+		- type_schema	(Schema of the type)
+		- type_name		(Type name)
+		- member_kind	(label, attribute, constraint)
+		- member_name	(Label, attribute name or constraint name)
+		- member_value	(Data type of an attribute or definition of a constraint. Empty for labels)
+		- position		(Position of the member in the type)
+
+	*/
+	GetUserDefinedTypeMembersQueryStatement() string
+	/*
+		A SQL query that brings the schemas. Implementations are expected to provide the following columns:
+		- schema_name	(Schema name)
+		- owner			(Role that owns the schema)
+		- comment		(Schema comment)
+
+	*/
+	GetSchemasQueryStatement() string
+	/*
+		A SQL query that brings the privileges granted on the schemas, their entities and the columns of the entities,
+		one row per privilege and grantee. Implementations are expected to provide the following columns:
+		- object_schema	(Schema of the object)
+		- object_name	(Name of the entity. Empty for grants on the schema)
+		- column_name	(Name of the column. Empty for grants on the schema or the whole entity)
+		- grantee		(Role that receives the privilege, or PUBLIC)
+		- grantor		(Role that granted the privilege)
+		- privilege		(SELECT, INSERT, UPDATE, DELETE, TRUNCATE, REFERENCES, TRIGGER, USAGE, CREATE...)
+		- is_grantable	(Whether the grantee can grant the privilege to other roles)
+
+	*/
+	GetGrantsQueryStatement() string
+	/*
+		A SQL query that brings the row-level security policies of the entities. Implementations are expected to
+		provide the following columns:
+		- table_schema 		(Schema of the entity)
+		- table_name   		(Entity name)
+		- policy_name		(The name of the policy)
+		- command			(ALL, SELECT, INSERT, UPDATE, DELETE)
+		- roles				(Comma separated list of the roles the policy applies to)
+		- is_permissive		(Whether the policy is permissive or restrictive)
+		- using_expression	(Expression of the USING clause. Empty if there is none)
+		- with_check_expression	(Expression of the WITH CHECK clause. Empty if there is none)
+
+	*/
+	GetPoliciesQueryStatement() string
+	/*
+		A SQL query that brings the general information of the database the connection is for, in a single row.
+		Implementations are expected to provide the following columns:
+		- database_name		(Database name)
+		- server_version	(Version of the database server)
+		- encoding			(Character set encoding of the database)
+		- collation			(Collation of the database)
+		- character_type	(Character classification of the database)
+		- comment			(Database comment)
+
+	*/
+	GetDatabaseQueryStatement() string
+	/*
+		A SQL query that brings the extensions installed in the database. Implementations are expected to provide the
+		following columns:
+		- extension_name	(Extension name)
+		- version			(Installed version of the extension)
+		- extension_schema	(Schema where the objects of the extension are created)
+		- comment			(Extension comment)
+
+	*/
+	GetExtensionsQueryStatement() string
+	/*
+		A SQL query that brings the values of the server settings listed in the input. Implementations are expected to
+		provide the following columns:
+		- setting_name	(Setting name)
+		- value			(Current value of the setting)
+		- unit			(Unit of the value. Empty if it has none)
+		- description	(Short description of the setting)
+
+	*/
+	GetSettingsQueryStatement() string
+	/*
+		A SQL query that brings the storage statistics of the entities with storage. Implementations are expected to
+		provide the following columns:
+		- table_schema 		(Schema of the entity)
+		- table_name   		(Entity name)
+		- estimated_rows	(Number of rows estimated by the database. Null if the entity was never analyzed)
+		- table_size		(Size in bytes of the data of the entity)
+		- indexes_size		(Size in bytes of the indexes of the entity)
+		- toast_size		(Size in bytes of the values stored out of line)
+		- total_size		(Total size in bytes, including indexes and out of line values)
+		- live_tuples		(Number of live rows)
+		- dead_tuples		(Number of dead rows)
+		- last_vacuum		(Last time the entity was vacuumed manually. Null if never)
+		- last_autovacuum	(Last time the entity was vacuumed by the autovacuum daemon. Null if never)
+		- last_analyze		(Last time the entity was analyzed manually. Null if never)
+		- last_autoanalyze	(Last time the entity was analyzed by the autovacuum daemon. Null if never)
+
+	*/
+	GetStorageStatsQueryStatement() string
+	/*
+		A SQL query that counts the rows of an entity. Implementations are expected to provide a single row with the
+		following column:
+		- row_count	(Number of rows in the entity)
+
+	*/
+	GetRowCountQueryStatement(schemaName string, entityName string) string
+	/*
+		A SQL query that brings the statistics of the planner about the columns. Implementations are expected to provide
+		the following columns:
+		- table_schema 			(Schema of the entity)
+		- table_name   			(Entity name)
+		- column_name  			(The name of the column)
+		- null_fraction			(Fraction of rows where the column is null)
+		- distinct_count		(Estimated number of distinct non-null values)
+		- average_width			(Average size in bytes of the values)
+		- most_common_values	(JSON array with the most common values as strings. Null if there are none or they
+								 are suppressed)
+		- most_common_freqs		(JSON array with the frequencies of the most common values. Null if there are none or
+								 they are suppressed)
+		- histogram_bounds		(JSON array with the histogram bounds as strings. Null if there are none or they are
+								 suppressed)
+		- correlation			(Correlation between the physical order of the rows and the order of the values. Null
+								 if unknown)
+		- row_count				(Estimated number of rows of the entity the statistics were computed for)
+
+	*/
+	GetColumnStatsQueryStatement() string
+	/*
+		A SQL query that brings a sample of the rows of an entity, with the values of the columns in `columnNames` as
+		text, in the same order. The sample is taken from `samplePercent` percent of the storage of the entity (the whole
+		entity if it is not positive) and limited to `rowLimit` rows (no limit if it is not positive).
+
+	*/
+	GetSampleRowsQueryStatement(
+		schemaName string, entityName string, columnNames []string, samplePercent float64, rowLimit int) string
+	/*
+		A SQL query that brings the most frequent non-null values of a column, at most `limit`, ordered by frequency.
+		Implementations are expected to provide the following columns:
+		- value			(The value as text)
+		- row_count		(Number of rows with the value)
+		- total_count	(Number of rows where the column is not null)
+
+	*/
+	GetValueFrequenciesQueryStatement(schemaName string, entityName string, columnName string, limit int) string
+	/*
+		A SQL query that checks if the values of a column are values of a column of another entity, looking at `limit`
+		distinct non-null values of the first column (all if not positive). Implementations are expected to provide a
+		single row with the following columns:
+		- checked_count	(Number of distinct values checked)
+		- missing_count	(Number of checked values that are not in the column of the other entity)
+
+	*/
+	GetValueInclusionQueryStatement(
+		schemaName string,
+		entityName string,
+		columnName string,
+		foreignSchemaName string,
+		foreignEntityName string,
+		foreignColumnName string,
+		limit int) string
+	/*
+		A SQL query that has the same columns as the result of `query` but no rows, so the columns can be described
+		without reading any row of the query. `query` is a single statement without trailing semicolon, and its text
+		must appear unchanged in the statement.
+
+	*/
+	GetQueryShapeQueryStatement(query string) string
+	/*
+		A SQL statement that prepares `query` with the name `statementName`, so the query is parsed and its tables,
+		columns and types are checked without executing it. `query` has no trailing semicolon, and its text must appear
+		unchanged in the statement.
+
+	*/
+	GetPrepareQueryStatement(statementName string, query string) string
+	// A SQL statement that removes the prepared statement with the name `statementName`
+	GetDeallocateQueryStatement(statementName string) string
+	// Returns the message of an error returned by the database and the position (in characters, starting at 1) in the
+	// statement where it was found, or 0 if the error has no position
+	GetErrorDetails(err error) (string, int)
 }
@@ -1,5 +1,7 @@
 package connector
 
+import "time"
+
 /*
 Input parameters.
 
@@ -13,4 +15,62 @@ type Input struct {
 	Name     string
 	Schemas  []string
 	Db       string
+	// Move partitions under their partitioned table instead of listing them as entities of the schema
+	CollapsePartitions bool
+	// Include the source code of functions and procedures in the description
+	IncludeRoutineSource bool
+	// Names of the server settings whose values are included in the description
+	Settings []string
+	// Include the sizes, row estimates and maintenance information of the entities in the description
+	IncludeStorageStats bool
+	// Count the rows of the entities with storage, as long as the counts take less than ExactCountsBudget in total
+	ExactCounts       bool
+	ExactCountsBudget time.Duration
+	// Include the statistics of the planner about each column, keeping at most MaxMostCommonValues common values. The
+	// statistics of the columns in NoValueSampleSchemas do not include any value
+	IncludeColumnStats   bool
+	MaxMostCommonValues  int
+	NoValueSampleSchemas []string
+	// Profile the contents of the columns of tables and materialized views, reading at most ProfileSampleRows rows of
+	// each (no limit if not positive) from a ProfileSamplePercent sample of its pages (the whole table if not positive).
+	// ProfileTopValues is the number of most frequent values kept and ProfileConcurrency the number of entities
+	// profiled at the same time
+	Profile              bool
+	ProfileSampleRows    int
+	ProfileSamplePercent float64
+	ProfileTopValues     int
+	ProfileConcurrency   int
+	// List the distinct values of the columns with at most CategoryThreshold distinct values (none if not positive).
+	// Only the columns that match CategoryInclude (all if empty) and do not match CategoryExclude are considered
+	CategoryThreshold int
+	CategoryInclude   []string
+	CategoryExclude   []string
+	// Include the rows of the reference tables, which are the tables matching ReferenceTables and, if
+	// DetectReferenceTables is true, the small tables referenced by several fks. At most ReferenceMaxRows rows are read
+	ReferenceTables       []string
+	DetectReferenceTables bool
+	ReferenceMaxRows      int
+	// Infer the structure of the documents of json and jsonb columns from JsonSchemaSamples values (not inferred if
+	// not positive)
+	JsonSchemaSamples int
+	// Include SampleRows example rows of each entity (none if not positive). MaskingRules have the format
+	// pattern=strategy and mask the values of the columns matching the pattern in the whole description. The pattern
+	// can also be classification:name to mask the columns with a classification or category matching name
+	SampleRows   int
+	MaskingRules []string
+	// Classify the columns that may contain sensitive data, with the rules in ClassificationConfigFile (the default
+	// rules if empty) and, if ClassificationSamples is positive, checking the values of that number of rows
+	Classify                 bool
+	ClassificationConfigFile string
+	ClassificationSamples    int
+	// Infer the relations that are not declared from the names and types of the columns and, if InferRelationsSamples
+	// is positive, check that that number of values of the referencing column are in the referenced one
+	InferRelations        bool
+	InferRelationsSamples int
+	// Look for candidate keys of the tables without a primary key in up to CandidateKeySamples rows (not looked for if
+	// not positive)
+	CandidateKeySamples int
+	// Describe the results of the queries in QueryFiles (SQL files or directories of .sql files) and QueryTexts
+	QueryFiles []string
+	QueryTexts []string
 }
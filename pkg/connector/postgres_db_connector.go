@@ -2,10 +2,12 @@ package connector
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 /*
@@ -37,17 +39,26 @@ func (dbConnector PostgresDBConnector) GetConnection() (*sql.DB, error) {
 }
 
 func (dbConnector PostgresDBConnector) GetEntitiesQueryStatement() string {
+	// information_schema.tables does not include materialized views, so pg_class is used instead
 	queryTemplate :=
-		`SELECT 
-		table_schema, table_name, table_type,
-		COALESCE(obj_description(
-		('"' || table_schema || '"."' || table_name || '"')::regclass,
-		'pg_class'), '') AS comment
-    FROM 
-		information_schema.tables WHERE table_schema in ([SCHEMAS])
-		ORDER BY table_name`
+		`SELECT
+		ns.nspname AS table_schema,
+		tbl.relname AS table_name,
+		[TABLE_TYPE] AS table_type,
+		COALESCE(obj_description(tbl.oid, 'pg_class'), '') AS comment,
+		pg_get_userbyid(tbl.relowner) AS owner,
+		tbl.relrowsecurity AS is_row_security_enabled,
+		tbl.relforcerowsecurity AS is_row_security_forced
+	FROM
+		pg_class tbl
+		JOIN pg_namespace ns ON ns.oid = tbl.relnamespace
+	WHERE
+		ns.nspname in ([SCHEMAS])
+		AND tbl.relkind IN ('r', 'v', 'm', 'f', 'p')
+	ORDER BY table_name`
 
 	query := strings.Replace(queryTemplate, "[SCHEMAS]", getFormattedSchemaList(dbConnector.Input.Schemas), -1)
+	query = strings.Replace(query, "[TABLE_TYPE]", getEntityTypeCase("tbl"), -1)
 	return query
 }
 
@@ -64,14 +75,21 @@ func (dbConnector PostgresDBConnector) GetColumnsQueryStatement() string {
 		 WHERE con.contype = 'p' AND con.conrelid = tbl.oid AND col.attnum = ANY(con.conkey)) AS is_primary_key,
 		(SELECT CASE WHEN con.conname IS NULL THEN FALSE ELSE TRUE END
 		 FROM pg_constraint con
-		 WHERE con.contype = 'f' AND con.conrelid = tbl.oid AND col.attnum = ANY(con.conkey)) AS is_foreign_key
+		 WHERE con.contype = 'f' AND con.conrelid = tbl.oid AND col.attnum = ANY(con.conkey)) AS is_foreign_key,
+		NOT col.attnotnull AS is_nullable,
+		CASE WHEN typ.typtype IN ('e', 'd', 'c') AND typ_ns.nspname NOT IN ('pg_catalog', 'information_schema')
+			THEN typ_ns.nspname ELSE '' END AS type_schema,
+		CASE WHEN typ.typtype IN ('e', 'd', 'c') AND typ_ns.nspname NOT IN ('pg_catalog', 'information_schema')
+			THEN typ.typname ELSE '' END AS type_name
 	FROM
 		pg_namespace ns
 		JOIN pg_class tbl ON tbl.relnamespace = ns.oid
 		JOIN pg_attribute col ON col.attrelid = tbl.oid
+		JOIN pg_type typ ON typ.oid = col.atttypid
+		JOIN pg_namespace typ_ns ON typ_ns.oid = typ.typnamespace
 	WHERE
 		ns.nspname in ([SCHEMAS])
-		AND tbl.relkind IN ('r', 'v')
+		AND tbl.relkind IN ('r', 'v', 'm', 'f', 'p')
 		AND col.attnum > 0 -- Exclude system columns
 	ORDER BY
 		schema_name,
@@ -83,25 +101,830 @@ func (dbConnector PostgresDBConnector) GetColumnsQueryStatement() string {
 }
 
 func (dbConnector PostgresDBConnector) GetRelationsQueryStatement() string {
+	// Constraints cloned from a parent constraint (partitions) are skipped so each fk is reported once
+	queryTemplate :=
+		`SELECT
+		ns.nspname AS table_schema,
+		con.conname AS constraint_name,
+		tbl.relname AS table_name,
+		col.attname AS column_name,
+		foreign_ns.nspname AS foreign_table_schema,
+		foreign_tbl.relname AS foreign_table_name,
+		foreign_col.attname AS foreign_column_name,
+		keys.position,
+		[ON_DELETE] AS on_delete,
+		[ON_UPDATE] AS on_update,
+		CASE con.confmatchtype WHEN 'f' THEN 'FULL' WHEN 'p' THEN 'PARTIAL' ELSE 'SIMPLE' END AS match_type,
+		con.condeferrable AS is_deferrable,
+		con.condeferred AS is_initially_deferred
+	FROM
+		pg_constraint con
+		JOIN pg_class tbl ON tbl.oid = con.conrelid
+		JOIN pg_namespace ns ON ns.oid = tbl.relnamespace
+		JOIN pg_class foreign_tbl ON foreign_tbl.oid = con.confrelid
+		JOIN pg_namespace foreign_ns ON foreign_ns.oid = foreign_tbl.relnamespace
+		CROSS JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS keys(column_number, foreign_column_number, position)
+		JOIN pg_attribute col ON col.attrelid = con.conrelid AND col.attnum = keys.column_number
+		JOIN pg_attribute foreign_col ON foreign_col.attrelid = con.confrelid AND foreign_col.attnum = keys.foreign_column_number
+	WHERE
+		con.contype = 'f'
+		AND con.conparentid = 0
+		AND ns.nspname in ([SCHEMAS])
+	ORDER BY
+		table_schema,
+		table_name,
+		constraint_name,
+		keys.position;`
+
+	query := strings.Replace(queryTemplate, "[SCHEMAS]", getFormattedSchemaList(dbConnector.Input.Schemas), -1)
+	query = strings.Replace(query, "[ON_DELETE]", getReferentialActionCase("con.confdeltype"), -1)
+	query = strings.Replace(query, "[ON_UPDATE]", getReferentialActionCase("con.confupdtype"), -1)
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetCheckConstraintsQueryStatement() string {
+	queryTemplate :=
+		`SELECT
+		ns.nspname AS table_schema,
+		tbl.relname AS table_name,
+		con.conname AS constraint_name,
+		pg_get_constraintdef(con.oid) AS expression,
+		COALESCE((SELECT string_agg(col.attname, ',' ORDER BY col.attnum)
+		 FROM pg_attribute col
+		 WHERE col.attrelid = tbl.oid AND col.attnum = ANY(con.conkey)), '') AS column_names
+	FROM
+		pg_constraint con
+		JOIN pg_class tbl ON tbl.oid = con.conrelid
+		JOIN pg_namespace ns ON ns.oid = tbl.relnamespace
+	WHERE
+		con.contype = 'c'
+		AND ns.nspname in ([SCHEMAS])
+	ORDER BY
+		table_schema,
+		table_name,
+		constraint_name;`
+
+	query := strings.Replace(queryTemplate, "[SCHEMAS]", getFormattedSchemaList(dbConnector.Input.Schemas), -1)
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetUniqueKeysQueryStatement() string {
+	// Partial and expression indexes are excluded as they do not make the columns themselves unique
+	queryTemplate :=
+		`SELECT
+		ns.nspname AS table_schema,
+		tbl.relname AS table_name,
+		idx.relname AS key_name,
+		string_agg(col.attname, ',' ORDER BY keys.position) AS column_names,
+		ind.indisprimary AS is_primary_key
+	FROM
+		pg_index ind
+		JOIN pg_class idx ON idx.oid = ind.indexrelid
+		JOIN pg_class tbl ON tbl.oid = ind.indrelid
+		JOIN pg_namespace ns ON ns.oid = tbl.relnamespace
+		CROSS JOIN LATERAL unnest(ind.indkey::int2[]) WITH ORDINALITY AS keys(column_number, position)
+		JOIN pg_attribute col ON col.attrelid = tbl.oid AND col.attnum = keys.column_number
+	WHERE
+		ind.indisunique
+		AND ind.indpred IS NULL
+		AND ind.indexprs IS NULL
+		AND keys.position <= ind.indnkeyatts
+		AND ns.nspname in ([SCHEMAS])
+	GROUP BY
+		ns.nspname,
+		tbl.relname,
+		idx.relname,
+		ind.indisprimary
+	ORDER BY
+		table_schema,
+		table_name,
+		key_name;`
+
+	query := strings.Replace(queryTemplate, "[SCHEMAS]", getFormattedSchemaList(dbConnector.Input.Schemas), -1)
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetViewDefinitionsQueryStatement() string {
+	queryTemplate :=
+		`SELECT
+		ns.nspname AS table_schema,
+		tbl.relname AS table_name,
+		pg_get_viewdef(tbl.oid, true) AS view_definition
+	FROM
+		pg_class tbl
+		JOIN pg_namespace ns ON ns.oid = tbl.relnamespace
+	WHERE
+		ns.nspname in ([SCHEMAS])
+		AND tbl.relkind IN ('v', 'm')
+	ORDER BY
+		table_schema,
+		table_name;`
+
+	query := strings.Replace(queryTemplate, "[SCHEMAS]", getFormattedSchemaList(dbConnector.Input.Schemas), -1)
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetViewDependenciesQueryStatement() string {
+	// A view depends on other relations through its rewrite rule. Views outside the described schemas are included
+	// when they read an entity in those schemas, as they are affected by changes in it
+	queryTemplate :=
+		`SELECT DISTINCT
+		view_ns.nspname AS view_schema,
+		view_tbl.relname AS view_name,
+		[VIEW_TYPE] AS view_type,
+		dep_ns.nspname AS table_schema,
+		dep_tbl.relname AS table_name,
+		[TABLE_TYPE] AS table_type
+	FROM
+		pg_depend dep
+		JOIN pg_rewrite rw ON rw.oid = dep.objid
+		JOIN pg_class view_tbl ON view_tbl.oid = rw.ev_class
+		JOIN pg_namespace view_ns ON view_ns.oid = view_tbl.relnamespace
+		JOIN pg_class dep_tbl ON dep_tbl.oid = dep.refobjid
+		JOIN pg_namespace dep_ns ON dep_ns.oid = dep_tbl.relnamespace
+	WHERE
+		dep.classid = 'pg_rewrite'::regclass
+		AND dep.refclassid = 'pg_class'::regclass
+		AND dep.deptype = 'n'
+		AND dep_tbl.oid <> view_tbl.oid
+		AND (view_ns.nspname in ([SCHEMAS]) OR dep_ns.nspname in ([SCHEMAS]))
+	ORDER BY
+		view_schema,
+		view_name,
+		table_schema,
+		table_name;`
+
+	query := strings.Replace(queryTemplate, "[SCHEMAS]", getFormattedSchemaList(dbConnector.Input.Schemas), -1)
+	query = strings.Replace(query, "[VIEW_TYPE]", getEntityTypeCase("view_tbl"), -1)
+	query = strings.Replace(query, "[TABLE_TYPE]", getEntityTypeCase("dep_tbl"), -1)
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetViewColumnUsageQueryStatement() string {
+	// Same information as information_schema.view_column_usage, which only shows tables owned by the current user
+	queryTemplate :=
+		`SELECT DISTINCT
+		view_ns.nspname AS view_schema,
+		view_tbl.relname AS view_name,
+		dep_ns.nspname AS table_schema,
+		dep_tbl.relname AS table_name,
+		col.attname AS column_name
+	FROM
+		pg_depend dep
+		JOIN pg_rewrite rw ON rw.oid = dep.objid
+		JOIN pg_class view_tbl ON view_tbl.oid = rw.ev_class
+		JOIN pg_namespace view_ns ON view_ns.oid = view_tbl.relnamespace
+		JOIN pg_class dep_tbl ON dep_tbl.oid = dep.refobjid
+		JOIN pg_namespace dep_ns ON dep_ns.oid = dep_tbl.relnamespace
+		JOIN pg_attribute col ON col.attrelid = dep.refobjid AND col.attnum = dep.refobjsubid
+	WHERE
+		dep.classid = 'pg_rewrite'::regclass
+		AND dep.refclassid = 'pg_class'::regclass
+		AND dep.refobjsubid > 0
+		AND dep_tbl.oid <> view_tbl.oid
+		AND view_ns.nspname in ([SCHEMAS])
+	ORDER BY
+		view_schema,
+		view_name,
+		table_schema,
+		table_name,
+		column_name;`
+
+	query := strings.Replace(queryTemplate, "[SCHEMAS]", getFormattedSchemaList(dbConnector.Input.Schemas), -1)
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetInheritanceQueryStatement() string {
+	// pg_inherits also links partitioned indexes, so only tables are kept
+	queryTemplate :=
+		`SELECT
+		child_ns.nspname AS child_schema,
+		child.relname AS child_name,
+		[CHILD_TYPE] AS child_type,
+		parent_ns.nspname AS parent_schema,
+		parent.relname AS parent_name,
+		[PARENT_TYPE] AS parent_type,
+		COALESCE(pg_get_expr(child.relpartbound, child.oid), '') AS partition_bound
+	FROM
+		pg_inherits inh
+		JOIN pg_class child ON child.oid = inh.inhrelid
+		JOIN pg_namespace child_ns ON child_ns.oid = child.relnamespace
+		JOIN pg_class parent ON parent.oid = inh.inhparent
+		JOIN pg_namespace parent_ns ON parent_ns.oid = parent.relnamespace
+	WHERE
+		child.relkind IN ('r', 'f', 'p')
+		AND (child_ns.nspname in ([SCHEMAS]) OR parent_ns.nspname in ([SCHEMAS]))
+	ORDER BY
+		parent_schema,
+		parent_name,
+		child_schema,
+		child_name;`
+
+	query := strings.Replace(queryTemplate, "[SCHEMAS]", getFormattedSchemaList(dbConnector.Input.Schemas), -1)
+	query = strings.Replace(query, "[CHILD_TYPE]", getEntityTypeCase("child"), -1)
+	query = strings.Replace(query, "[PARENT_TYPE]", getEntityTypeCase("parent"), -1)
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetPartitionKeysQueryStatement() string {
+	// pg_get_partkeydef returns the strategy followed by the key in parenthesis, like RANGE (created_at)
+	queryTemplate :=
+		`SELECT
+		ns.nspname AS table_schema,
+		tbl.relname AS table_name,
+		CASE pt.partstrat WHEN 'r' THEN 'range' WHEN 'l' THEN 'list' WHEN 'h' THEN 'hash' ELSE '' END AS partition_strategy,
+		regexp_replace(pg_get_partkeydef(tbl.oid), '^\w+ \((.*)\)$', '\1') AS partition_key
+	FROM
+		pg_partitioned_table pt
+		JOIN pg_class tbl ON tbl.oid = pt.partrelid
+		JOIN pg_namespace ns ON ns.oid = tbl.relnamespace
+	WHERE
+		ns.nspname in ([SCHEMAS])
+	ORDER BY
+		table_schema,
+		table_name;`
+
+	query := strings.Replace(queryTemplate, "[SCHEMAS]", getFormattedSchemaList(dbConnector.Input.Schemas), -1)
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetRoutinesQueryStatement() string {
+	// Routines that belong to extensions are not part of the schema design, so they are skipped.
+	// proallargtypes is only set when there are OUT arguments, otherwise proargtypes has all the arguments
+	queryTemplate :=
+		`SELECT
+		ns.nspname AS routine_schema,
+		p.proname AS routine_name,
+		p.proname || '(' || pg_get_function_identity_arguments(p.oid) || ')' AS routine_signature,
+		CASE p.prokind WHEN 'p' THEN 'procedure' WHEN 'a' THEN 'aggregate' WHEN 'w' THEN 'window' ELSE 'function' END AS routine_kind,
+		COALESCE(pg_get_function_result(p.oid), '') AS return_type,
+		lang.lanname AS language,
+		CASE p.provolatile WHEN 'i' THEN 'immutable' WHEN 's' THEN 'stable' ELSE 'volatile' END AS volatility,
+		p.prosecdef AS is_security_definer,
+		COALESCE(obj_description(p.oid, 'pg_proc'), '') AS comment,
+		[SOURCE] AS source,
+		COALESCE(args.position, 0) AS argument_position,
+		COALESCE(p.proargnames[args.position], '') AS argument_name,
+		COALESCE(format_type(args.type_oid, NULL), '') AS argument_type,
+		CASE COALESCE(p.proargmodes[args.position], 'i')
+			WHEN 'o' THEN 'OUT' WHEN 'b' THEN 'INOUT' WHEN 'v' THEN 'VARIADIC' WHEN 't' THEN 'TABLE' ELSE 'IN'
+		END AS argument_mode
+	FROM
+		pg_proc p
+		JOIN pg_namespace ns ON ns.oid = p.pronamespace
+		JOIN pg_language lang ON lang.oid = p.prolang
+		LEFT JOIN LATERAL unnest(COALESCE(p.proallargtypes, p.proargtypes::oid[]))
+			WITH ORDINALITY AS args(type_oid, position) ON TRUE
+	WHERE
+		ns.nspname in ([SCHEMAS])
+		AND NOT EXISTS (
+			SELECT 1 FROM pg_depend dep
+			WHERE dep.classid = 'pg_proc'::regclass AND dep.objid = p.oid AND dep.deptype = 'e')
+	ORDER BY
+		routine_schema,
+		routine_signature,
+		argument_position;`
+
+	source := "''"
+	if dbConnector.Input.IncludeRoutineSource {
+		source = "COALESCE(p.prosrc, '')"
+	}
+	query := strings.Replace(queryTemplate, "[SCHEMAS]", getFormattedSchemaList(dbConnector.Input.Schemas), -1)
+	query = strings.Replace(query, "[SOURCE]", source, -1)
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetTriggersQueryStatement() string {
+	// tgtype is a bit mask: 1 row level, 2 before, 4 insert, 8 delete, 16 update, 32 truncate, 64 instead of.
+	// There is no function to get the WHEN condition alone, so it is taken from the trigger definition
+	queryTemplate :=
+		`SELECT
+		ns.nspname AS table_schema,
+		tbl.relname AS table_name,
+		trg.tgname AS trigger_name,
+		CASE
+			WHEN trg.tgtype::integer & 2 <> 0 THEN 'BEFORE'
+			WHEN trg.tgtype::integer & 64 <> 0 THEN 'INSTEAD OF'
+			ELSE 'AFTER'
+		END AS timing,
+		concat_ws(',',
+			CASE WHEN trg.tgtype::integer & 4 <> 0 THEN 'INSERT' END,
+			CASE WHEN trg.tgtype::integer & 16 <> 0 THEN 'UPDATE' END,
+			CASE WHEN trg.tgtype::integer & 8 <> 0 THEN 'DELETE' END,
+			CASE WHEN trg.tgtype::integer & 32 <> 0 THEN 'TRUNCATE' END) AS events,
+		CASE WHEN trg.tgtype::integer & 1 <> 0 THEN 'ROW' ELSE 'STATEMENT' END AS level,
+		fn_ns.nspname AS function_schema,
+		fn.proname AS function_name,
+		[ENABLED] AS enabled,
+		COALESCE(substring(pg_get_triggerdef(trg.oid, true) FROM 'WHEN \((.*)\) EXECUTE (?:FUNCTION|PROCEDURE)'), '') AS condition,
+		COALESCE(obj_description(trg.oid, 'pg_trigger'), '') AS comment
+	FROM
+		pg_trigger trg
+		JOIN pg_class tbl ON tbl.oid = trg.tgrelid
+		JOIN pg_namespace ns ON ns.oid = tbl.relnamespace
+		JOIN pg_proc fn ON fn.oid = trg.tgfoid
+		JOIN pg_namespace fn_ns ON fn_ns.oid = fn.pronamespace
+	WHERE
+		NOT trg.tgisinternal
+		AND ns.nspname in ([SCHEMAS])
+	ORDER BY
+		table_schema,
+		table_name,
+		trigger_name;`
+
+	query := strings.Replace(queryTemplate, "[SCHEMAS]", getFormattedSchemaList(dbConnector.Input.Schemas), -1)
+	query = strings.Replace(query, "[ENABLED]", getTriggerEnabledCase("trg.tgenabled"), -1)
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetEventTriggersQueryStatement() string {
+	query :=
+		`SELECT
+		evt.evtname AS trigger_name,
+		evt.evtevent AS event,
+		COALESCE(array_to_string(evt.evttags, ','), '') AS tags,
+		fn_ns.nspname AS function_schema,
+		fn.proname AS function_name,
+		[ENABLED] AS enabled,
+		COALESCE(obj_description(evt.oid, 'pg_event_trigger'), '') AS comment
+	FROM
+		pg_event_trigger evt
+		JOIN pg_proc fn ON fn.oid = evt.evtfoid
+		JOIN pg_namespace fn_ns ON fn_ns.oid = fn.pronamespace
+	ORDER BY
+		trigger_name;`
+
+	return strings.Replace(query, "[ENABLED]", getTriggerEnabledCase("evt.evtenabled"), -1)
+}
+
+func (dbConnector PostgresDBConnector) GetSequencesQueryStatement() string {
+	// Serial columns own their sequence with an auto dependency ('a'), identity columns with an internal one ('i')
+	queryTemplate :=
+		`SELECT
+		seq.schemaname AS sequence_schema,
+		seq.sequencename AS sequence_name,
+		seq.data_type::text AS data_type,
+		seq.start_value,
+		seq.last_value AS current_value,
+		seq.increment_by AS increment,
+		seq.min_value,
+		seq.max_value,
+		seq.cache_size,
+		seq.cycle AS is_cycle,
+		COALESCE(owner_ns.nspname, '') AS owner_schema,
+		COALESCE(owner_tbl.relname, '') AS owner_table,
+		COALESCE(owner_col.attname, '') AS owner_column,
+		COALESCE(dep.deptype = 'i', FALSE) AS is_identity
+	FROM
+		pg_sequences seq
+		JOIN pg_namespace ns ON ns.nspname = seq.schemaname
+		JOIN pg_class cls ON cls.relnamespace = ns.oid AND cls.relname = seq.sequencename
+		LEFT JOIN pg_depend dep ON dep.classid = 'pg_class'::regclass
+			AND dep.objid = cls.oid
+			AND dep.refclassid = 'pg_class'::regclass
+			AND dep.refobjsubid > 0
+			AND dep.deptype IN ('a', 'i')
+		LEFT JOIN pg_class owner_tbl ON owner_tbl.oid = dep.refobjid
+		LEFT JOIN pg_namespace owner_ns ON owner_ns.oid = owner_tbl.relnamespace
+		LEFT JOIN pg_attribute owner_col ON owner_col.attrelid = dep.refobjid AND owner_col.attnum = dep.refobjsubid
+	WHERE
+		seq.schemaname in ([SCHEMAS])
+	ORDER BY
+		sequence_schema,
+		sequence_name;`
+
+	query := strings.Replace(queryTemplate, "[SCHEMAS]", getFormattedSchemaList(dbConnector.Input.Schemas), -1)
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetUserDefinedTypesQueryStatement() string {
+	// Composite types are kept only if they were created with CREATE TYPE (relkind 'c'), not the row types of tables.
+	// Types that belong to extensions are skipped
+	queryTemplate :=
+		`SELECT
+		ns.nspname AS type_schema,
+		typ.typname AS type_name,
+		CASE typ.typtype WHEN 'e' THEN 'enum' WHEN 'd' THEN 'domain' ELSE 'composite' END AS type_kind,
+		CASE WHEN typ.typtype = 'd' THEN format_type(typ.typbasetype, typ.typtypmod) ELSE '' END AS base_type,
+		typ.typnotnull AS is_not_null,
+		COALESCE(typ.typdefault, '') AS default_value,
+		COALESCE(obj_description(typ.oid, 'pg_type'), '') AS comment
+	FROM
+		pg_type typ
+		JOIN pg_namespace ns ON ns.oid = typ.typnamespace
+		LEFT JOIN pg_class cls ON cls.oid = typ.typrelid
+	WHERE
+		ns.nspname in ([SCHEMAS])
+		AND (typ.typtype IN ('e', 'd') OR (typ.typtype = 'c' AND cls.relkind = 'c'))
+		AND NOT EXISTS (
+			SELECT 1 FROM pg_depend dep
+			WHERE dep.classid = 'pg_type'::regclass AND dep.objid = typ.oid AND dep.deptype = 'e')
+	ORDER BY
+		type_schema,
+		type_name;`
+
+	query := strings.Replace(queryTemplate, "[SCHEMAS]", getFormattedSchemaList(dbConnector.Input.Schemas), -1)
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetUserDefinedTypeMembersQueryStatement() string {
+	queryTemplate :=
+		`SELECT
+		ns.nspname AS type_schema,
+		typ.typname AS type_name,
+		'label' AS member_kind,
+		e.enumlabel::text AS member_name,
+		'' AS member_value,
+		row_number() OVER (PARTITION BY typ.oid ORDER BY e.enumsortorder) AS position
+	FROM
+		pg_enum e
+		JOIN pg_type typ ON typ.oid = e.enumtypid
+		JOIN pg_namespace ns ON ns.oid = typ.typnamespace
+	WHERE
+		ns.nspname in ([SCHEMAS])
+	UNION ALL
+	SELECT
+		ns.nspname,
+		typ.typname,
+		'attribute',
+		att.attname::text,
+		format_type(att.atttypid, att.atttypmod),
+		att.attnum
+	FROM
+		pg_type typ
+		JOIN pg_namespace ns ON ns.oid = typ.typnamespace
+		JOIN pg_class cls ON cls.oid = typ.typrelid AND cls.relkind = 'c'
+		JOIN pg_attribute att ON att.attrelid = cls.oid AND att.attnum > 0 AND NOT att.attisdropped
+	WHERE
+		ns.nspname in ([SCHEMAS])
+	UNION ALL
+	SELECT
+		ns.nspname,
+		typ.typname,
+		'constraint',
+		con.conname::text,
+		pg_get_constraintdef(con.oid),
+		row_number() OVER (PARTITION BY typ.oid ORDER BY con.conname)
+	FROM
+		pg_constraint con
+		JOIN pg_type typ ON typ.oid = con.contypid
+		JOIN pg_namespace ns ON ns.oid = typ.typnamespace
+	WHERE
+		con.contype = 'c'
+		AND ns.nspname in ([SCHEMAS])
+	ORDER BY
+		1, 2, 3, 6;`
+
+	query := strings.Replace(queryTemplate, "[SCHEMAS]", getFormattedSchemaList(dbConnector.Input.Schemas), -1)
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetSchemasQueryStatement() string {
+	queryTemplate :=
+		`SELECT
+		ns.nspname AS schema_name,
+		pg_get_userbyid(ns.nspowner) AS owner,
+		COALESCE(obj_description(ns.oid, 'pg_namespace'), '') AS comment
+	FROM
+		pg_namespace ns
+	WHERE
+		ns.nspname in ([SCHEMAS])
+	ORDER BY
+		schema_name;`
+
+	query := strings.Replace(queryTemplate, "[SCHEMAS]", getFormattedSchemaList(dbConnector.Input.Schemas), -1)
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetGrantsQueryStatement() string {
+	// A null acl means the default privileges, which acldefault provides for schemas ('n') and relations ('r').
+	// Columns only have the privileges granted explicitly on them. Grantee 0 is PUBLIC
+	queryTemplate :=
+		`SELECT
+		ns.nspname AS object_schema,
+		'' AS object_name,
+		'' AS column_name,
+		[GRANTEE] AS grantee,
+		pg_get_userbyid(acl.grantor) AS grantor,
+		acl.privilege_type AS privilege,
+		acl.is_grantable
+	FROM
+		pg_namespace ns
+		CROSS JOIN LATERAL aclexplode(COALESCE(ns.nspacl, acldefault('n', ns.nspowner))) acl
+	WHERE
+		ns.nspname in ([SCHEMAS])
+	UNION ALL
+	SELECT
+		ns.nspname,
+		tbl.relname,
+		'',
+		[GRANTEE],
+		pg_get_userbyid(acl.grantor),
+		acl.privilege_type,
+		acl.is_grantable
+	FROM
+		pg_class tbl
+		JOIN pg_namespace ns ON ns.oid = tbl.relnamespace
+		CROSS JOIN LATERAL aclexplode(COALESCE(tbl.relacl, acldefault('r', tbl.relowner))) acl
+	WHERE
+		ns.nspname in ([SCHEMAS])
+		AND tbl.relkind IN ('r', 'v', 'm', 'f', 'p')
+	UNION ALL
+	SELECT
+		ns.nspname,
+		tbl.relname,
+		col.attname,
+		[GRANTEE],
+		pg_get_userbyid(acl.grantor),
+		acl.privilege_type,
+		acl.is_grantable
+	FROM
+		pg_attribute col
+		JOIN pg_class tbl ON tbl.oid = col.attrelid
+		JOIN pg_namespace ns ON ns.oid = tbl.relnamespace
+		CROSS JOIN LATERAL aclexplode(col.attacl) acl
+	WHERE
+		ns.nspname in ([SCHEMAS])
+		AND tbl.relkind IN ('r', 'v', 'm', 'f', 'p')
+		AND col.attnum > 0
+		AND NOT col.attisdropped
+		AND col.attacl IS NOT NULL
+	ORDER BY
+		1, 2, 3, 4, 6;`
+
+	query := strings.Replace(queryTemplate, "[SCHEMAS]", getFormattedSchemaList(dbConnector.Input.Schemas), -1)
+	query = strings.Replace(query, "[GRANTEE]", "CASE WHEN acl.grantee = 0 THEN 'PUBLIC' ELSE pg_get_userbyid(acl.grantee) END", -1)
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetPoliciesQueryStatement() string {
+	queryTemplate :=
+		`SELECT
+		pol.schemaname AS table_schema,
+		pol.tablename AS table_name,
+		pol.policyname AS policy_name,
+		pol.cmd AS command,
+		array_to_string(pol.roles, ',') AS roles,
+		pol.permissive = 'PERMISSIVE' AS is_permissive,
+		COALESCE(pol.qual, '') AS using_expression,
+		COALESCE(pol.with_check, '') AS with_check_expression
+	FROM
+		pg_policies pol
+	WHERE
+		pol.schemaname in ([SCHEMAS])
+	ORDER BY
+		table_schema,
+		table_name,
+		policy_name;`
+
+	query := strings.Replace(queryTemplate, "[SCHEMAS]", getFormattedSchemaList(dbConnector.Input.Schemas), -1)
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetDatabaseQueryStatement() string {
+	query :=
+		`SELECT
+		db.datname AS database_name,
+		current_setting('server_version') AS server_version,
+		pg_encoding_to_char(db.encoding) AS encoding,
+		db.datcollate AS collation,
+		db.datctype AS character_type,
+		COALESCE(shobj_description(db.oid, 'pg_database'), '') AS comment
+	FROM
+		pg_database db
+	WHERE
+		db.datname = current_database();`
+
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetExtensionsQueryStatement() string {
+	query :=
+		`SELECT
+		ext.extname AS extension_name,
+		ext.extversion AS version,
+		ns.nspname AS extension_schema,
+		COALESCE(obj_description(ext.oid, 'pg_extension'), '') AS comment
+	FROM
+		pg_extension ext
+		JOIN pg_namespace ns ON ns.oid = ext.extnamespace
+	ORDER BY
+		extension_name;`
+
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetSettingsQueryStatement() string {
+	// Setting names are case insensitive
 	queryTemplate :=
 		`SELECT
-		tc.table_schema, 
-		tc.constraint_name, 
-		tc.table_name, 
-		kcu.column_name, 
-		ccu.table_schema AS foreign_table_schema,
-		ccu.table_name AS foreign_table_name,
-		ccu.column_name AS foreign_column_name 
-	FROM 
-		information_schema.table_constraints AS tc 
-		JOIN information_schema.key_column_usage AS kcu
-		  ON tc.constraint_name = kcu.constraint_name
-		  AND tc.table_schema = kcu.table_schema
-		JOIN information_schema.constraint_column_usage AS ccu
-		  ON ccu.constraint_name = tc.constraint_name
-		  AND ccu.table_schema = tc.table_schema
-	WHERE tc.constraint_type = 'FOREIGN KEY' AND tc.table_schema in ([SCHEMAS]);`
+		st.name AS setting_name,
+		st.setting AS value,
+		COALESCE(st.unit, '') AS unit,
+		st.short_desc AS description
+	FROM
+		pg_settings st
+	WHERE
+		lower(st.name) in ([SETTINGS])
+	ORDER BY
+		setting_name;`
 
+	settings := make([]string, 0, len(dbConnector.Input.Settings))
+	for _, s := range dbConnector.Input.Settings {
+		settings = append(settings, strings.ToLower(s))
+	}
+	query := strings.Replace(queryTemplate, "[SETTINGS]", getFormattedStringList(settings), -1)
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetStorageStatsQueryStatement() string {
+	// reltuples is -1 for tables that were never analyzed (0 before Postgres 14)
+	queryTemplate :=
+		`SELECT
+		ns.nspname AS table_schema,
+		tbl.relname AS table_name,
+		CASE WHEN tbl.reltuples < 0 THEN NULL ELSE tbl.reltuples::bigint END AS estimated_rows,
+		pg_relation_size(tbl.oid) AS table_size,
+		pg_indexes_size(tbl.oid) AS indexes_size,
+		COALESCE(pg_total_relation_size(NULLIF(tbl.reltoastrelid, 0)), 0) AS toast_size,
+		pg_total_relation_size(tbl.oid) AS total_size,
+		COALESCE(stat.n_live_tup, 0) AS live_tuples,
+		COALESCE(stat.n_dead_tup, 0) AS dead_tuples,
+		stat.last_vacuum,
+		stat.last_autovacuum,
+		stat.last_analyze,
+		stat.last_autoanalyze
+	FROM
+		pg_class tbl
+		JOIN pg_namespace ns ON ns.oid = tbl.relnamespace
+		LEFT JOIN pg_stat_user_tables stat ON stat.relid = tbl.oid
+	WHERE
+		ns.nspname in ([SCHEMAS])
+		AND tbl.relkind IN ('r', 'm', 'p')
+	ORDER BY
+		table_schema,
+		table_name;`
+
+	query := strings.Replace(queryTemplate, "[SCHEMAS]", getFormattedSchemaList(dbConnector.Input.Schemas), -1)
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetRowCountQueryStatement(schemaName string, entityName string) string {
+	return "SELECT count(*) AS row_count FROM " + quoteIdentifier(schemaName) + "." + quoteIdentifier(entityName)
+}
+
+func (dbConnector PostgresDBConnector) GetColumnStatsQueryStatement() string {
+	// n_distinct is negative when it is a proportion of the number of rows. Parents of inheritance trees have stats of
+	// their own rows and of the whole tree; the former are preferred. Values of array columns are arrays themselves, so
+	// they are not converted to arrays of text
+	queryTemplate :=
+		`SELECT DISTINCT ON (st.schemaname, st.tablename, st.attname)
+		st.schemaname AS table_schema,
+		st.tablename AS table_name,
+		st.attname AS column_name,
+		st.null_frac AS null_fraction,
+		(CASE WHEN st.n_distinct >= 0 THEN st.n_distinct ELSE -st.n_distinct * GREATEST(tbl.reltuples, 0) END)::bigint
+			AS distinct_count,
+		st.avg_width AS average_width,
+		CASE WHEN [SAMPLES_SUPPRESSED] THEN NULL
+			ELSE array_to_json((st.most_common_vals::text::text[])[1:[MAX_MCV]]) END AS most_common_values,
+		CASE WHEN [SAMPLES_SUPPRESSED] THEN NULL
+			ELSE array_to_json(st.most_common_freqs[1:[MAX_MCV]]) END AS most_common_freqs,
+		CASE WHEN [SAMPLES_SUPPRESSED] THEN NULL
+			ELSE array_to_json(st.histogram_bounds::text::text[]) END AS histogram_bounds,
+		st.correlation,
+		GREATEST(tbl.reltuples, 0)::bigint AS row_count
+	FROM
+		pg_stats st
+		JOIN pg_namespace ns ON ns.nspname = st.schemaname
+		JOIN pg_class tbl ON tbl.relnamespace = ns.oid AND tbl.relname = st.tablename
+		JOIN pg_attribute col ON col.attrelid = tbl.oid AND col.attname = st.attname
+		JOIN pg_type typ ON typ.oid = col.atttypid
+	WHERE
+		st.schemaname in ([SCHEMAS])
+	ORDER BY
+		st.schemaname,
+		st.tablename,
+		st.attname,
+		st.inherited;`
+
+	samplesSuppressed := "typ.typcategory = 'A' OR st.schemaname in (" +
+		getFormattedStringList(dbConnector.Input.NoValueSampleSchemas) + ")"
+	maxMostCommonValues := dbConnector.Input.MaxMostCommonValues
+	if maxMostCommonValues < 0 {
+		maxMostCommonValues = 0
+	}
 	query := strings.Replace(queryTemplate, "[SCHEMAS]", getFormattedSchemaList(dbConnector.Input.Schemas), -1)
+	query = strings.Replace(query, "[SAMPLES_SUPPRESSED]", samplesSuppressed, -1)
+	query = strings.Replace(query, "[MAX_MCV]", strconv.Itoa(maxMostCommonValues), -1)
 	return query
 }
+
+func (dbConnector PostgresDBConnector) GetSampleRowsQueryStatement(
+	schemaName string, entityName string, columnNames []string, samplePercent float64, rowLimit int) string {
+	columns := make([]string, 0, len(columnNames))
+	for _, c := range columnNames {
+		columns = append(columns, quoteIdentifier(c)+"::text")
+	}
+
+	query := "SELECT " + strings.Join(columns, ", ") + " FROM " + quoteIdentifier(schemaName) + "." +
+		quoteIdentifier(entityName)
+	// SYSTEM sampling reads random pages instead of the whole table, which is much faster on big tables
+	if samplePercent > 0 && samplePercent < 100 {
+		query += " TABLESAMPLE SYSTEM (" + strconv.FormatFloat(samplePercent, 'f', -1, 64) + ")"
+	}
+	if rowLimit > 0 {
+		query += " LIMIT " + strconv.Itoa(rowLimit)
+	}
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetValueFrequenciesQueryStatement(
+	schemaName string, entityName string, columnName string, limit int) string {
+	// The window function is computed before the limit, so it counts the rows of all the values
+	queryTemplate :=
+		`SELECT
+		[COLUMN]::text AS value,
+		count(*) AS row_count,
+		sum(count(*)) OVER ()::bigint AS total_count
+	FROM
+		[TABLE]
+	WHERE
+		[COLUMN] IS NOT NULL
+	GROUP BY
+		[COLUMN]
+	ORDER BY
+		row_count DESC,
+		value
+	LIMIT [LIMIT];`
+
+	query := strings.Replace(queryTemplate, "[COLUMN]", quoteIdentifier(columnName), -1)
+	query = strings.Replace(query, "[TABLE]", quoteIdentifier(schemaName)+"."+quoteIdentifier(entityName), -1)
+	query = strings.Replace(query, "[LIMIT]", strconv.Itoa(limit), -1)
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetValueInclusionQueryStatement(
+	schemaName string,
+	entityName string,
+	columnName string,
+	foreignSchemaName string,
+	foreignEntityName string,
+	foreignColumnName string,
+	limit int) string {
+	// The values are compared without casts so an index on the foreign column can be used
+	queryTemplate :=
+		`SELECT
+		count(*) AS checked_count,
+		count(*) FILTER (
+			WHERE NOT EXISTS (SELECT 1 FROM [FOREIGN_TABLE] f WHERE f.[FOREIGN_COLUMN] = s.value)
+		) AS missing_count
+	FROM
+		(
+			SELECT DISTINCT [COLUMN] AS value
+			FROM [TABLE]
+			WHERE [COLUMN] IS NOT NULL
+			[LIMIT]
+		) s;`
+
+	limitClause := ""
+	if limit > 0 {
+		limitClause = "LIMIT " + strconv.Itoa(limit)
+	}
+	query := strings.Replace(queryTemplate, "[COLUMN]", quoteIdentifier(columnName), -1)
+	query = strings.Replace(query, "[TABLE]", quoteIdentifier(schemaName)+"."+quoteIdentifier(entityName), -1)
+	query = strings.Replace(query, "[FOREIGN_COLUMN]", quoteIdentifier(foreignColumnName), -1)
+	query = strings.Replace(
+		query, "[FOREIGN_TABLE]", quoteIdentifier(foreignSchemaName)+"."+quoteIdentifier(foreignEntityName), -1)
+	query = strings.Replace(query, "[LIMIT]", limitClause, -1)
+	return query
+}
+
+func (dbConnector PostgresDBConnector) GetQueryShapeQueryStatement(query string) string {
+	// The query goes in its own lines so a comment in its last line does not hide the rest of the statement
+	queryTemplate :=
+		`SELECT * FROM (
+[QUERY]
+) AS query LIMIT 0;`
+
+	return strings.Replace(queryTemplate, "[QUERY]", query, -1)
+}
+
+func (dbConnector PostgresDBConnector) GetPrepareQueryStatement(statementName string, query string) string {
+	// The query goes in its own lines so a comment in its last line does not hide the rest of the statement
+	return "PREPARE " + quoteIdentifier(statementName) + " AS\n" + query + "\n;"
+}
+
+func (dbConnector PostgresDBConnector) GetDeallocateQueryStatement(statementName string) string {
+	return "DEALLOCATE " + quoteIdentifier(statementName) + ";"
+}
+
+func (dbConnector PostgresDBConnector) GetErrorDetails(err error) (string, int) {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err.Error(), 0
+	}
+	// The position is only set for errors in the statement itself, not in the functions it calls
+	position, _ := strconv.Atoi(pqErr.Position)
+	return pqErr.Message, position
+}
@@ -11,3 +11,44 @@ func getFormattedSchemaList(schemaList []string) string {
 	}
 	return strings.Join(formattedSchemasList, ",")
 }
+
+// Helper method to format a list of values as a list of SQL string literals. An empty list is formatted as NULL, so
+// `IN (...)` filters using it match nothing instead of being invalid.
+func getFormattedStringList(values []string) string {
+	if len(values) == 0 {
+		return "NULL"
+	}
+	formattedValues := make([]string, 0, len(values))
+	for _, v := range values {
+		formattedValues = append(formattedValues, "'"+strings.ReplaceAll(v, "'", "''")+"'")
+	}
+	return strings.Join(formattedValues, ",")
+}
+
+// Helper method to quote an identifier (schema, table or column name) so it can be used in a SQL query whatever
+// characters it contains.
+func quoteIdentifier(identifier string) string {
+	return "\"" + strings.ReplaceAll(identifier, "\"", "\"\"") + "\""
+}
+
+// Helper method to translate the code of a referential action of a fk in pg_constraint into its name.
+func getReferentialActionCase(columnName string) string {
+	return "CASE " + columnName +
+		" WHEN 'r' THEN 'RESTRICT' WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL' WHEN 'd' THEN 'SET DEFAULT'" +
+		" ELSE 'NO ACTION' END"
+}
+
+// Helper method to get the type of an entity from its row in pg_class (`tableAlias` is the alias of pg_class in the
+// query), using the type names of information_schema plus the types it does not cover.
+func getEntityTypeCase(tableAlias string) string {
+	return "CASE WHEN " + tableAlias + ".relispartition THEN 'PARTITION'" +
+		" ELSE CASE " + tableAlias + ".relkind" +
+		" WHEN 'v' THEN 'VIEW' WHEN 'm' THEN 'MATERIALIZED VIEW' WHEN 'f' THEN 'FOREIGN TABLE'" +
+		" WHEN 'p' THEN 'PARTITIONED TABLE' ELSE 'BASE TABLE' END END"
+}
+
+// Helper method to translate the firing mode of a trigger (tgenabled, evtenabled) into a name.
+func getTriggerEnabledCase(columnName string) string {
+	return "CASE " + columnName +
+		" WHEN 'D' THEN 'DISABLED' WHEN 'R' THEN 'REPLICA' WHEN 'A' THEN 'ALWAYS' ELSE 'ENABLED' END"
+}
@@ -0,0 +1,14 @@
+package model
+
+/*
+A representation of a set of columns that could be the primary key of an entity that does not have one.
+
+The columns of a CandidateKey have no nulls and their values are unique in the SampledRows rows that were read.
+IsVerified is true when those rows were all the rows of the entity, so the columns are unique in its current data;
+otherwise they are only unique in the sample.
+*/
+type CandidateKey struct {
+	ColumnNames []string
+	SampledRows int64
+	IsVerified  bool
+}
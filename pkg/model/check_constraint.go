@@ -0,0 +1,15 @@
+package model
+
+/*
+A representation of a check constraint defined on an entity.
+
+The CheckConstraint struct contains the name of the constraint, its expression as reported by the database and the
+names of the columns involved in it.
+*/
+type CheckConstraint struct {
+	SchemaName  string
+	EntityName  string
+	Name        string
+	Expression  string
+	ColumnNames []string
+}
@@ -0,0 +1,15 @@
+package model
+
+/*
+A representation of a kind of sensitive data a column may contain, like an email or a date of birth.
+
+Category is PII (personally identifiable information) or PHI (protected health information). Confidence (between 0
+and 1) combines the confidence of all the evidence found, and Evidence describes each piece: the rule matched by the
+name of the column, the keyword found in its comment or the fraction of sampled values matched by a detector.
+*/
+type Classification struct {
+	Name       string
+	Category   string
+	Confidence float64
+	Evidence   []string
+}
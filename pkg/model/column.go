@@ -4,14 +4,45 @@ package model
 A representation of a database column.
 
 Column contains data that can be extracted from the database. Main data is the name, type and comment. The rest is to
-be able to identify the Entity it belongs to.
+be able to identify the Entity it belongs to. Name is lowercased; OriginalName keeps the case it has in the database,
+which is needed to query the column when its name is not all lowercase.
+
+AllowedValues and ValueRange describe the domain of the column when it can be derived from its check constraints.
+For columns of views, Lineage lists the columns of other entities the column derives from. Serial and identity columns
+have the schema and name of the sequence they own in SequenceSchemaName and SequenceName. When the data type is a
+user-defined type, TypeSchemaName and TypeName identify it; for enums and domains AllowedValues is taken from the type.
+Stats contains the statistics of the planner about the column, when they are requested and available, and Profile the
+profile computed from the actual contents of the column when the database is profiled. For columns with few distinct
+values, CategoricalValues lists all of them with their frequencies when requested. Columns that reference the code of
+a reference table have the codes and labels of the table in ReferenceValues. For json and jsonb columns, JsonSchema is
+the structure of the documents inferred from a sample, when requested.
+
+Classifications lists the kinds of sensitive data the column may contain, when classification is requested. When the
+values of the column are masked, MaskingStrategy is the strategy used (hash, redact, fake), and every value of the
+column in the description (samples, statistics, profile, lists of values) is masked with it.
 */
 type Column struct {
-	SchemaName   string
-	EntityName   string
-	Name         string
-	DataType     string
-	Comment      string
-	IsPrimaryKey bool
-	IsForeignKey bool
+	SchemaName         string
+	EntityName         string
+	Name               string
+	OriginalName       string
+	DataType           string
+	Comment            string
+	IsPrimaryKey       bool
+	IsForeignKey       bool
+	IsNullable         bool
+	AllowedValues      []string
+	ValueRange         *ValueRange
+	Lineage            []ColumnLineage
+	SequenceSchemaName string
+	SequenceName       string
+	TypeSchemaName     string
+	TypeName           string
+	Stats              *ColumnStats
+	Profile            *ColumnProfile
+	CategoricalValues  []ValueFrequency
+	ReferenceValues    []ReferenceValue
+	JsonSchema         *JsonSchema
+	Classifications    []Classification
+	MaskingStrategy    string
 }
@@ -0,0 +1,16 @@
+package model
+
+/*
+A representation of the origin of a column of a view.
+
+ColumnLineage identifies a column of another entity the view column derives from. IsPassthrough is true when the view
+column is a plain copy of the source column. Otherwise the view column is computed by an expression, which is kept in
+Expression.
+*/
+type ColumnLineage struct {
+	SourceSchemaName string
+	SourceEntityName string
+	SourceColumnName string
+	IsPassthrough    bool
+	Expression       string
+}
@@ -0,0 +1,12 @@
+package model
+
+/*
+A pair of columns linked by a foreign key.
+
+ColumnPair contains the name of a column in the referencing entity and the name of the column it references in the
+foreign entity.
+*/
+type ColumnPair struct {
+	ColumnName        string
+	ForeignColumnName string
+}
@@ -0,0 +1,26 @@
+package model
+
+/*
+A representation of the profile of the contents of a column, computed from a sample of the rows of its entity.
+
+SampledRows is the number of rows in the sample. NullCount and DistinctCount are the number of nulls and of distinct
+non-null values in the sample, and Min and Max the smallest and largest values (compared as numbers for numeric
+columns). The lengths of the values as text are summarised by MinLength, MaxLength, AverageLength and
+LengthDistribution.
+
+TopValues lists the most frequent values and Patterns the known formats (uuid, date, ontology_id...) matched by the
+values, both with the number of values and the fraction of non-null values they represent.
+*/
+type ColumnProfile struct {
+	SampledRows        int64
+	NullCount          int64
+	DistinctCount      int64
+	Min                string
+	Max                string
+	MinLength          int
+	MaxLength          int
+	AverageLength      float64
+	LengthDistribution []LengthBucket
+	TopValues          []ValueFrequency
+	Patterns           []ValueFrequency
+}
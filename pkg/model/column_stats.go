@@ -0,0 +1,20 @@
+package model
+
+/*
+A representation of the statistics the database planner keeps about a column.
+
+NullFraction is the fraction of rows (between 0 and 1) where the column is null, DistinctCount the estimated number of
+distinct non-null values and AverageWidth the average size in bytes of the values. MostCommonValues lists the most
+common values with their frequencies and their counts, estimated from the number of rows of the entity, and
+HistogramBounds the values that divide the rest of values into groups of approximately the same size. Both are empty
+when value samples are suppressed for the schema of the column.
+Correlation measures how the physical order of the rows matches the order of the values (from -1 to 1).
+*/
+type ColumnStats struct {
+	NullFraction     float64
+	DistinctCount    int64
+	AverageWidth     int
+	MostCommonValues []ValueFrequency
+	HistogramBounds  []string
+	Correlation      *float64
+}
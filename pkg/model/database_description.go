@@ -9,10 +9,24 @@ import (
 /*
 A container for the different Schemas for which descriptions where extracted.
 
-DatabaseDescription contains a slice of `Schema`.
+DatabaseDescription contains a slice of `Schema` and the objects that belong to the whole database, like event triggers.
+It also has general information about the database: its name, the version of the server, the encoding and collation,
+the comment of the database, the installed extensions and the values of the selected server settings. Queries has the
+description of the result of SQL queries, like saved reports, as entities of type [Query] that do not belong to any
+schema.
 */
 type DatabaseDescription struct {
-	Schemas []Schema
+	Name          string
+	ServerVersion string
+	Encoding      string
+	Collation     string
+	CharacterType string
+	Comment       string
+	Extensions    []Extension
+	Settings      []Setting
+	Schemas       []Schema
+	EventTriggers []EventTrigger
+	Queries       []Entity
 }
 
 // Returns a string representation of the DatabaseDescription struct.
@@ -3,21 +3,67 @@ package model
 import "fmt"
 
 /*
-A representation of a database entity (table, view, for example).
+A representation of a database entity (table, view, for example). The possible types are listed in [EntityType].
 
 Entity struct contains data that can be extracted from the database, like the name and the comment. It also has a slice
-of [Column].
+of [Column]. SchemaName and Name are lowercased; OriginalSchemaName and OriginalName keep the case they have in the
+database, which is needed to query the entity when its name is not all lowercase.
+
+Relations contains the foreign keys defined in the entity and ReferencedBy the foreign keys in other entities that
+reference it. IsJunctionTable is true for tables that only exist to link 2 entities in a many-to-many relationship.
+
+For views and queries, ViewDefinition contains the query and Dependencies the entities the query reads. Dependents lists,
+for any entity, the views that read it, that is, the views that would break if the entity was dropped.
+
+Parents and Children describe table inheritance, including partitioning: a partition has its partitioned table as
+parent. Partitioned tables have a PartitionStrategy (range, list, hash) and a PartitionKey, and partitions have a
+PartitionBound. When partitions are collapsed, they are moved from the schema into the Partitions of their parent.
+
+Owner is the role that owns the entity and Grants the privileges granted on it or on its columns. When row-level
+security is enabled, the rows each role can access are limited by the Policies of the entity; when it is forced, the
+policies also apply to the owner.
+
+Storage contains the size and row count of the entity and when it was last vacuumed and analyzed. It is only set when
+storage statistics or exact row counts are requested. For reference tables (small tables of codes referenced by other
+tables), ReferenceData contains their rows. SampleRows contains some example rows of the entity, when requested. For
+tables without a primary key, CandidateKeys lists the smallest sets of columns that are unique in their data, when
+requested.
 */
 type Entity struct {
-	SchemaName string
-	Name       string
-	EntityType string
-	Columns    []Column
-	Relations  []Relation
-	Comment    string
+	SchemaName           string
+	Name                 string
+	OriginalSchemaName   string
+	OriginalName         string
+	EntityType           EntityType
+	Columns              []Column
+	Relations            []Relation
+	Comment              string
+	CheckConstraints     []CheckConstraint
+	UniqueKeys           []UniqueKey
+	ReferencedBy         []Relation
+	IsJunctionTable      bool
+	ViewDefinition       string
+	Dependencies         []EntityReference
+	Dependents           []EntityReference
+	Parents              []EntityReference
+	Children             []EntityReference
+	PartitionStrategy    string
+	PartitionKey         string
+	PartitionBound       string
+	Partitions           []Entity
+	Triggers             []Trigger
+	Owner                string
+	Grants               []Grant
+	IsRowSecurityEnabled bool
+	IsRowSecurityForced  bool
+	Policies             []Policy
+	Storage              *StorageStats
+	ReferenceData        *ReferenceData
+	SampleRows           *RowSet
+	CandidateKeys        []CandidateKey
 }
 
 // Returns a string representation of the Entity struct.
 func (e Entity) String() string {
-	return fmt.Sprintf("[%+v, %s, %s, %s, %s]", e.SchemaName, e.Name, e.EntityType, e.Columns, e.Comment)
+	return fmt.Sprintf("[%+v, %s, %s, %v, %s]", e.SchemaName, e.Name, e.EntityType, e.Columns, e.Comment)
 }
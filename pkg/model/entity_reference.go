@@ -0,0 +1,13 @@
+package model
+
+/*
+A reference to an entity.
+
+EntityReference identifies an entity by its schema and name. It is used to link entities without copying their whole
+description, for example to represent the dependencies between views and the entities they read.
+*/
+type EntityReference struct {
+	SchemaName string
+	EntityName string
+	EntityType EntityType
+}
@@ -0,0 +1,16 @@
+package model
+
+// The type of a database entity.
+type EntityType string
+
+// Types of the entities that can be extracted from a database.
+const (
+	Table            EntityType = "table"
+	View             EntityType = "view"
+	MaterializedView EntityType = "materialized_view"
+	ForeignTable     EntityType = "foreign_table"
+	PartitionedTable EntityType = "partitioned_table"
+	Partition        EntityType = "partition"
+	// The result of a SQL query that is not stored in the database, like a saved report
+	Query EntityType = "query"
+)
@@ -0,0 +1,17 @@
+package model
+
+/*
+A representation of a database-level event trigger.
+
+Event is the event that fires the trigger (ddl_command_start, ddl_command_end, sql_drop, table_rewrite...) and Tags
+the command tags it is restricted to, if any. Enabled is one of ENABLED, DISABLED, REPLICA or ALWAYS.
+*/
+type EventTrigger struct {
+	Name           string
+	Event          string
+	Tags           []string
+	FunctionSchema string
+	FunctionName   string
+	Enabled        string
+	Comment        string
+}
@@ -0,0 +1,13 @@
+package model
+
+/*
+A representation of an extension installed in the database.
+
+SchemaName is the schema where the objects of the extension were created.
+*/
+type Extension struct {
+	Name       string
+	Version    string
+	SchemaName string
+	Comment    string
+}
@@ -0,0 +1,18 @@
+package model
+
+/*
+A representation of a privilege granted to a role on a schema, an entity or a column of an entity.
+
+Grants on a schema have an empty EntityName, and grants on a whole entity an empty ColumnName. Grantee is the name of
+the role receiving the privilege, or PUBLIC for all roles. Privilege is the name of the privilege (SELECT, INSERT,
+USAGE...) and IsGrantable is true when the grantee can grant it to other roles.
+*/
+type Grant struct {
+	SchemaName  string
+	EntityName  string
+	ColumnName  string
+	Grantee     string
+	Grantor     string
+	Privilege   string
+	IsGrantable bool
+}
@@ -0,0 +1,24 @@
+package model
+
+/*
+A representation of the structure of JSON documents as a JSON Schema, inferred from a sample of the documents.
+
+Unlike the rest of the model, the fields are serialised with the standard JSON Schema keywords, so the schema can be
+used by JSON Schema tools. Type lists all the types observed (object, array, string, number, integer, boolean, null).
+Properties and Required describe objects: a property is required when it was present in every sampled object.
+Objects whose keys look like data, because there are too many different keys, are described by AdditionalProperties
+instead. Items describes the elements of arrays.
+
+The observed frequencies are kept in extension keywords: Count is the number of sampled values described by the
+schema and Frequency, for properties, the fraction of the sampled objects that had the property.
+*/
+type JsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Type                 []string               `json:"type,omitempty"`
+	Properties           map[string]*JsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *JsonSchema            `json:"additionalProperties,omitempty"`
+	Items                *JsonSchema            `json:"items,omitempty"`
+	Count                int64                  `json:"x-count"`
+	Frequency            float64                `json:"x-frequency,omitempty"`
+}
@@ -0,0 +1,11 @@
+package model
+
+/*
+A representation of a group of values whose length is between MinLength and MaxLength (both inclusive), and how many
+values are in the group.
+*/
+type LengthBucket struct {
+	MinLength int
+	MaxLength int
+	Count     int64
+}
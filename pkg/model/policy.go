@@ -0,0 +1,19 @@
+package model
+
+/*
+A representation of a row-level security policy of an entity.
+
+Command is the command the policy applies to (ALL, SELECT, INSERT, UPDATE, DELETE) and Roles the roles it applies to.
+Permissive policies are combined with OR and restrictive ones with AND. Using is the expression that filters the rows
+that can be read or modified, and WithCheck the expression the new rows must satisfy.
+*/
+type Policy struct {
+	SchemaName   string
+	EntityName   string
+	Name         string
+	Command      string
+	Roles        []string
+	IsPermissive bool
+	Using        string
+	WithCheck    string
+}
@@ -0,0 +1,13 @@
+package model
+
+/*
+A representation of a problem found when checking a SQL query against a database, like a table that does not exist.
+
+QueryIssue has the file of the query and the line (starting at 1) where the problem was found, or the line where the
+statement starts when the exact position is not known.
+*/
+type QueryIssue struct {
+	FileName string
+	Line     int
+	Message  string
+}
@@ -0,0 +1,15 @@
+package model
+
+/*
+A representation of the contents of a reference table, a small table that lists the codes other tables can use, like
+a list of types or statuses.
+
+CodeColumnName is the column that identifies each row and LabelColumnName, if any, the column that describes it.
+IsConfigured is true when the table was declared as a reference table instead of being detected.
+*/
+type ReferenceData struct {
+	CodeColumnName  string
+	LabelColumnName string
+	IsConfigured    bool
+	Rows            RowSet
+}
@@ -0,0 +1,10 @@
+package model
+
+/*
+A representation of a value a column can take because it is a code of a reference table, with the label that
+describes it in that table, if any.
+*/
+type ReferenceValue struct {
+	Code  string
+	Label string
+}
@@ -3,14 +3,39 @@ package model
 /*
 A representation of a relation between 2 entities.
 
-The Relation struct represents data about a foreign key.
+The Relation struct represents data about a foreign key. The columns of the key are kept as an ordered list of
+[ColumnPair], so composite keys are represented as a single relation. OnDelete and OnUpdate contain the referential
+actions (NO ACTION, RESTRICT, CASCADE, SET NULL, SET DEFAULT) and MatchType the match type (SIMPLE, FULL, PARTIAL).
+
+Cardinality is [OneToOne] when the columns of the foreign key are unique in the referencing entity and [OneToMany]
+otherwise. IsOptional is true when any column of the foreign key accepts nulls, so a row can exist without a related
+row in the foreign entity.
+
+Relations with IsInferred set are not declared in the database but proposed from the names and types of the columns
+and, optionally, their data. Confidence (between 0 and 1) tells how likely the relation is and Evidence lists what
+supports it. Declared relations have a Confidence of 1.
 */
 type Relation struct {
 	SchemaName          string
 	EntityName          string
 	RelationName        string
-	ColumnName          string
+	ColumnPairs         []ColumnPair
 	ForeignEntitySchema string
 	ForeignEntityName   string
-	ForeignColumnName   string
+	OnDelete            string
+	OnUpdate            string
+	MatchType           string
+	IsDeferrable        bool
+	IsInitiallyDeferred bool
+	Cardinality         string
+	IsOptional          bool
+	IsInferred          bool
+	Confidence          float64
+	Evidence            []string
 }
+
+// Cardinalities of a relation, read from the referenced entity to the referencing one.
+const (
+	OneToOne  = "one-to-one"
+	OneToMany = "one-to-many"
+)
@@ -0,0 +1,23 @@
+package model
+
+/*
+A representation of a function or procedure stored in the database.
+
+Routine contains the name of the routine and its Signature (name and argument types), which identifies overloaded
+routines. Kind is one of function, procedure, aggregate or window. Functions returning TABLE have the columns of the
+table in ReturnColumns. Source is only extracted when requested, as it can be long.
+*/
+type Routine struct {
+	SchemaName        string
+	Name              string
+	Signature         string
+	Kind              string
+	Arguments         []RoutineArgument
+	ReturnType        string
+	ReturnColumns     []RoutineArgument
+	Language          string
+	Volatility        string
+	IsSecurityDefiner bool
+	Comment           string
+	Source            string
+}
@@ -0,0 +1,12 @@
+package model
+
+/*
+A representation of an argument of a [Routine].
+
+Mode is one of IN, OUT, INOUT, VARIADIC or TABLE. Unnamed arguments have an empty Name.
+*/
+type RoutineArgument struct {
+	Name     string
+	DataType string
+	Mode     string
+}
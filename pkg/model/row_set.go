@@ -0,0 +1,13 @@
+package model
+
+/*
+A representation of a set of rows read from an entity.
+
+Rows contains the values of the columns in ColumnNames, in the same order, as text. Null values are nil. IsTruncated
+is true when the entity had more rows than the ones read.
+*/
+type RowSet struct {
+	ColumnNames []string
+	Rows        [][]*string
+	IsTruncated bool
+}
@@ -1,24 +1,28 @@
 package model
 
-import (
-	"strings"
-)
-
 /*
 A container for entities descritpions in the database.
 
-Schema struct contains the name of the schema (or namespace) and the slice of [Entity] that belong to it.
+Schema struct contains the name of the schema (or namespace) and the slice of [Entity] that belong to it. It also has
+the [Routine] (functions and procedures), [Sequence] and [UserDefinedType] defined in the schema, its Owner, Comment and
+the Grants on the schema itself.
 */
 type Schema struct {
-	Name     string
-	Entities []Entity
+	Name      string
+	Entities  []Entity
+	Routines  []Routine
+	Sequences []Sequence
+	Types     []UserDefinedType
+	Owner     string
+	Comment   string
+	Grants    []Grant
 }
 
-// Helper function to filter entities by type ("view", "table")
-func (s Schema) GetEntitiesByType(entityType string) []Entity {
+// Helper function to filter entities by type ([View], [Table], [MaterializedView]...)
+func (s Schema) GetEntitiesByType(entityType EntityType) []Entity {
 	var filtered []Entity
 	for _, e := range s.Entities {
-		if strings.ToLower(entityType) == strings.ToLower(e.EntityType) {
+		if entityType == e.EntityType {
 			filtered = append(filtered, e)
 		}
 	}
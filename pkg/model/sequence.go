@@ -0,0 +1,38 @@
+package model
+
+/*
+A representation of a database sequence.
+
+Sequence contains the settings of the sequence and its CurrentValue, which is nil when the sequence has not been used
+yet or cannot be read. When the sequence is owned by a column (serial and identity columns), OwnerSchemaName,
+OwnerEntityName and OwnerColumnName identify that column.
+*/
+type Sequence struct {
+	SchemaName      string
+	Name            string
+	DataType        string
+	StartValue      int64
+	CurrentValue    *int64
+	Increment       int64
+	MinValue        int64
+	MaxValue        int64
+	CacheSize       int64
+	IsCycle         bool
+	OwnerSchemaName string
+	OwnerEntityName string
+	OwnerColumnName string
+	IsIdentity      bool
+}
+
+// Returns the fraction (between 0 and 1) of the values of the sequence that have already been used. A value close to
+// 1 means that the sequence is about to be exhausted.
+func (s Sequence) UsedFraction() float64 {
+	if s.CurrentValue == nil || s.MaxValue <= s.MinValue {
+		return 0
+	}
+	total := float64(s.MaxValue) - float64(s.MinValue)
+	if s.Increment < 0 {
+		return (float64(s.MaxValue) - float64(*s.CurrentValue)) / total
+	}
+	return (float64(*s.CurrentValue) - float64(s.MinValue)) / total
+}
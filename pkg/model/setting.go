@@ -0,0 +1,13 @@
+package model
+
+/*
+A representation of a configuration setting of the database server.
+
+Value is the current value of the setting, expressed in Unit when the setting has one (kB, ms, 8kB...).
+*/
+type Setting struct {
+	Name        string
+	Value       string
+	Unit        string
+	Description string
+}
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+/*
+A representation of the storage used by an entity and its maintenance information.
+
+EstimatedRowCount is the number of rows estimated by the database the last time the entity was analyzed, nil if it was
+never analyzed, and ExactRowCount the result of counting the rows, nil if they were not counted. Sizes are in bytes:
+TableSize is the size of the data of the entity, IndexesSize the size of all its indexes, ToastSize the size of the
+values stored out of line and TotalSize the sum of all of them.
+
+LiveTuples and DeadTuples are the number of live and dead rows according to the statistics collector. The rest of
+fields are the last time the entity was vacuumed or analyzed, manually or by the autovacuum daemon, nil if never.
+*/
+type StorageStats struct {
+	EstimatedRowCount *int64
+	ExactRowCount     *int64
+	TableSize         int64
+	IndexesSize       int64
+	ToastSize         int64
+	TotalSize         int64
+	LiveTuples        int64
+	DeadTuples        int64
+	LastVacuum        *time.Time
+	LastAutovacuum    *time.Time
+	LastAnalyze       *time.Time
+	LastAutoanalyze   *time.Time
+}
@@ -0,0 +1,22 @@
+package model
+
+/*
+A representation of a trigger defined on an entity.
+
+Timing is one of BEFORE, AFTER or INSTEAD OF, Events contains the operations that fire the trigger (INSERT, UPDATE,
+DELETE, TRUNCATE) and Level is ROW or STATEMENT. Enabled is one of ENABLED, DISABLED, REPLICA or ALWAYS. Condition is
+the WHEN condition of the trigger, if any.
+*/
+type Trigger struct {
+	SchemaName     string
+	EntityName     string
+	Name           string
+	Timing         string
+	Events         []string
+	Level          string
+	FunctionSchema string
+	FunctionName   string
+	Enabled        string
+	Condition      string
+	Comment        string
+}
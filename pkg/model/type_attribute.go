@@ -0,0 +1,7 @@
+package model
+
+// A representation of an attribute of a composite [UserDefinedType].
+type TypeAttribute struct {
+	Name     string
+	DataType string
+}
@@ -0,0 +1,15 @@
+package model
+
+/*
+A representation of a set of columns whose values are unique in an entity.
+
+UniqueKey is built from the primary key and the unique constraints or indexes of an entity. Partial and expression
+indexes are not considered as they do not guarantee uniqueness of the columns themselves.
+*/
+type UniqueKey struct {
+	SchemaName   string
+	EntityName   string
+	Name         string
+	ColumnNames  []string
+	IsPrimaryKey bool
+}
@@ -0,0 +1,30 @@
+package model
+
+/*
+A representation of a type defined in the database by a user.
+
+Kind is one of enum, domain or composite. Enums have their EnumLabels in order. Domains have a BaseType, whether they
+accept nulls, a Default and their check Constraints (whose EntityName is the name of the domain); AllowedValues and
+ValueRange are derived from those constraints like for columns. Composite types have a list of Attributes.
+*/
+type UserDefinedType struct {
+	SchemaName    string
+	Name          string
+	Kind          string
+	Comment       string
+	EnumLabels    []string
+	BaseType      string
+	IsNotNull     bool
+	Default       string
+	Constraints   []CheckConstraint
+	AllowedValues []string
+	ValueRange    *ValueRange
+	Attributes    []TypeAttribute
+}
+
+// Kinds of user-defined types.
+const (
+	EnumType      = "enum"
+	DomainType    = "domain"
+	CompositeType = "composite"
+)
@@ -0,0 +1,13 @@
+package model
+
+/*
+A representation of a value of a column and how often it appears.
+
+Frequency is the fraction of rows (between 0 and 1) that have the value. Count is the number of rows with the value,
+when it is known.
+*/
+type ValueFrequency struct {
+	Value     string
+	Frequency float64
+	Count     int64
+}
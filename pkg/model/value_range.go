@@ -0,0 +1,14 @@
+package model
+
+/*
+A representation of the range of values accepted by a column.
+
+ValueRange is derived from check constraints that compare a column against literals. Min or Max are empty when the
+range is not bounded on that side.
+*/
+type ValueRange struct {
+	Min          string
+	MinInclusive bool
+	Max          string
+	MaxInclusive bool
+}
@@ -0,0 +1,93 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
+)
+
+/*
+Writes the lineage of the views in a [model/DatabaseDescription] as a graph in DOT format.
+
+Each entity that is read by a view, or is a view, is a node listing its columns. A grey edge goes from each entity to
+the views that read it, and an edge goes from each column to the view columns that derive from it. Edges of columns
+computed by expressions are dashed.
+*/
+func WriteLineageAsDot(databaseDescription model.DatabaseDescription, outputFileName string) {
+	var sb strings.Builder
+	sb.WriteString("digraph lineage {\n")
+	sb.WriteString("\trankdir=LR;\n")
+	sb.WriteString("\tnode [shape=record];\n")
+
+	for _, schema := range databaseDescription.Schemas {
+		for _, entity := range schema.Entities {
+			if len(entity.Dependencies) == 0 && len(entity.Dependents) == 0 {
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("\t%s [label=\"%s\"];\n", getDotNodeId(entity.SchemaName, entity.Name),
+				getDotRecordLabel(entity)))
+		}
+	}
+
+	for _, schema := range databaseDescription.Schemas {
+		for _, entity := range schema.Entities {
+			viewNode := getDotNodeId(entity.SchemaName, entity.Name)
+			for _, dependency := range entity.Dependencies {
+				sb.WriteString(fmt.Sprintf("\t%s -> %s [color=grey];\n",
+					getDotNodeId(dependency.SchemaName, dependency.EntityName), viewNode))
+			}
+			for _, column := range entity.Columns {
+				for _, lineage := range column.Lineage {
+					style := ""
+					if !lineage.IsPassthrough {
+						style = " [style=dashed]"
+					}
+					sb.WriteString(fmt.Sprintf("\t%s:%s -> %s:%s%s;\n",
+						getDotNodeId(lineage.SourceSchemaName, lineage.SourceEntityName),
+						getDotPortId(lineage.SourceColumnName),
+						viewNode,
+						getDotPortId(column.Name),
+						style))
+				}
+			}
+		}
+	}
+
+	sb.WriteString("}\n")
+	writeFile([]byte(sb.String()), outputFileName)
+	fmt.Println("Lineage graph created successfully.")
+}
+
+func getDotNodeId(schemaName string, entityName string) string {
+	return "\"" + escapeDotString(schemaName+"."+entityName) + "\""
+}
+
+func getDotPortId(columnName string) string {
+	return "\"" + escapeDotString(columnName) + "\""
+}
+
+// Builds the label of a record node: the name of the entity followed by a field for each column
+func getDotRecordLabel(entity model.Entity) string {
+	fields := []string{escapeDotRecordField(entity.SchemaName + "." + entity.Name)}
+	for _, column := range entity.Columns {
+		fields = append(fields, "<"+escapeDotRecordField(column.Name)+"> "+escapeDotRecordField(column.Name))
+	}
+	return "{" + strings.Join(fields, "|") + "}"
+}
+
+func escapeDotString(value string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(value, "\\", "\\\\"), "\"", "\\\"")
+}
+
+// Escapes the characters with a special meaning in the label of a record node
+func escapeDotRecordField(value string) string {
+	var sb strings.Builder
+	for _, r := range escapeDotString(value) {
+		if strings.ContainsRune("{}|<> ", r) {
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,154 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
+)
+
+/*
+Writes the privileges in a [model/DatabaseDescription] as a role-by-object matrix in CSV format.
+
+There is a row for each schema, each entity and each column with privileges granted on it, and a column for each role
+that received a privilege. Each cell lists the privileges of the role on the object, with a `*` after the ones the role
+can grant to others. The owner, the row-level security status (enabled or forced) and the names of the policies of
+each object are also included.
+*/
+func WritePermissionMatrixAsCsv(databaseDescription model.DatabaseDescription, outputFileName string) {
+	schemas := append([]model.Schema{}, databaseDescription.Schemas...)
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].Name < schemas[j].Name })
+
+	roles := getGrantees(schemas)
+
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	writeCsvRecord(writer, append([]string{"object_type", "object", "owner", "row_security", "policies"}, roles...))
+
+	for _, schema := range schemas {
+		writeCsvRecord(writer, getPermissionRecord("schema", schema.Name, schema.Owner, "", nil, schema.Grants, roles))
+		for _, entity := range sortEntities(schema.Entities) {
+			writeEntityPermissions(writer, entity, roles)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Fatal("Error writing CSV:", err)
+	}
+	writeFile(buffer.Bytes(), outputFileName)
+	fmt.Println("Permission matrix created successfully.")
+}
+
+// Writes the rows of an entity, its columns with privileges of their own and its collapsed partitions
+func writeEntityPermissions(writer *csv.Writer, entity model.Entity, roles []string) {
+	entityGrants := make([]model.Grant, 0)
+	// map with column name --> grants on the column
+	columnGrants := make(map[string][]model.Grant)
+	for _, g := range entity.Grants {
+		if g.ColumnName == "" {
+			entityGrants = append(entityGrants, g)
+		} else {
+			columnGrants[g.ColumnName] = append(columnGrants[g.ColumnName], g)
+		}
+	}
+
+	rowSecurity := ""
+	if entity.IsRowSecurityForced {
+		rowSecurity = "forced"
+	} else if entity.IsRowSecurityEnabled {
+		rowSecurity = "enabled"
+	}
+	policyNames := make([]string, 0, len(entity.Policies))
+	for _, p := range entity.Policies {
+		policyNames = append(policyNames, p.Name)
+	}
+
+	name := entity.SchemaName + "." + entity.Name
+	writeCsvRecord(writer, getPermissionRecord(
+		string(entity.EntityType), name, entity.Owner, rowSecurity, policyNames, entityGrants, roles))
+	for _, column := range entity.Columns {
+		if grants, hasGrants := columnGrants[column.Name]; hasGrants {
+			writeCsvRecord(writer, getPermissionRecord(
+				"column", name+"."+column.Name, entity.Owner, "", nil, grants, roles))
+		}
+	}
+
+	for _, partition := range sortEntities(entity.Partitions) {
+		writeEntityPermissions(writer, partition, roles)
+	}
+}
+
+// Builds the row of an object, with the privileges of each role in `roles` in the same order
+func getPermissionRecord(
+	objectType string,
+	objectName string,
+	owner string,
+	rowSecurity string,
+	policyNames []string,
+	grants []model.Grant,
+	roles []string) []string {
+	// map with role --> privileges of the role
+	privileges := make(map[string][]string)
+	for _, g := range grants {
+		privilege := g.Privilege
+		if g.IsGrantable {
+			privilege += "*"
+		}
+		privileges[g.Grantee] = append(privileges[g.Grantee], privilege)
+	}
+
+	record := []string{objectType, objectName, owner, rowSecurity, strings.Join(policyNames, " ")}
+	for _, role := range roles {
+		record = append(record, strings.Join(privileges[role], " "))
+	}
+	return record
+}
+
+// Returns the sorted list of the roles that received a privilege on any object of the schemas
+func getGrantees(schemas []model.Schema) []string {
+	seen := make(map[string]bool)
+	collect := func(grants []model.Grant) {
+		for _, g := range grants {
+			seen[g.Grantee] = true
+		}
+	}
+	var collectEntity func(entity model.Entity)
+	collectEntity = func(entity model.Entity) {
+		collect(entity.Grants)
+		for _, partition := range entity.Partitions {
+			collectEntity(partition)
+		}
+	}
+
+	for _, schema := range schemas {
+		collect(schema.Grants)
+		for _, entity := range schema.Entities {
+			collectEntity(entity)
+		}
+	}
+
+	roles := make([]string, 0, len(seen))
+	for role := range seen {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// Returns a copy of `entities` sorted by name
+func sortEntities(entities []model.Entity) []model.Entity {
+	sorted := append([]model.Entity{}, entities...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
+
+func writeCsvRecord(writer *csv.Writer, record []string) {
+	if err := writer.Write(record); err != nil {
+		log.Fatal("Error writing CSV:", err)
+	}
+}
@@ -1,10 +1,11 @@
 // Package report contains logic to report the descriptions of a database.
-// For now, a service to write a JSON file with the database descritions is provided.
+// A service to write a JSON file with the database descritions is provided, as well as other reports built from it.
 package report
 
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 
@@ -18,6 +19,30 @@ func WriteDbDescriptionAsJson(databaseDescription model.DatabaseDescription, out
 		log.Fatal("Error marshaling JSON:", err)
 	}
 
+	writeFile(jsonData, outputFileName)
+	fmt.Println("JSON file created successfully.")
+}
+
+// Writes the description of queries, entities of type [model/Query], as a JSON file.
+func WriteQueriesAsJson(queries []model.Entity, outputFileName string) {
+	jsonData, err := json.MarshalIndent(queries, "", "    ")
+	if err != nil {
+		log.Fatal("Error marshaling JSON:", err)
+	}
+
+	writeFile(jsonData, outputFileName)
+	fmt.Println("JSON file created successfully.")
+}
+
+// Writes the problems found in queries, one per line, as file:line: message, the format editors and CI tools recognise.
+func WriteQueryIssues(issues []model.QueryIssue, writer io.Writer) {
+	for _, issue := range issues {
+		fmt.Fprintf(writer, "%s:%d: %s\n", issue.FileName, issue.Line, issue.Message)
+	}
+}
+
+// Writes the content of a report to a file.
+func writeFile(data []byte, outputFileName string) {
 	// Open a file for writing
 	file, err := os.Create(outputFileName)
 	if err != nil {
@@ -25,11 +50,9 @@ func WriteDbDescriptionAsJson(databaseDescription model.DatabaseDescription, out
 	}
 	defer file.Close()
 
-	// Write the JSON data to the file
-	_, err = file.Write(jsonData)
+	// Write the data to the file
+	_, err = file.Write(data)
 	if err != nil {
-		log.Fatal("Error writing JSON to file:", err)
+		log.Fatal("Error writing to file:", err)
 	}
-
-	fmt.Println("JSON file created successfully.")
 }
@@ -0,0 +1,98 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PDCMFinder/db-descriptor/pkg/model"
+)
+
+/*
+Writes the columns of a [model/DatabaseDescription] classified as sensitive in CSV format.
+
+There is a row for each classification of each column, grouped by schema. A row with the totals of each schema (number
+of sensitive columns, of PII and PHI columns and of masked columns) precedes its columns. Each row of a column has the
+data type, the classification with its category and confidence, the evidence found, separated by `; `, and the
+masking strategy of the column, if its values are masked.
+*/
+func WriteSensitivityReportAsCsv(databaseDescription model.DatabaseDescription, outputFileName string) {
+	schemas := append([]model.Schema{}, databaseDescription.Schemas...)
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].Name < schemas[j].Name })
+
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	writeCsvRecord(writer, []string{"schema", "entity", "column", "data_type", "classification", "category",
+		"confidence", "evidence", "masking_strategy"})
+
+	for _, schema := range schemas {
+		columns := getSensitiveColumns(schema.Entities)
+		if len(columns) == 0 {
+			continue
+		}
+		writeCsvRecord(writer, getSchemaSensitivitySummary(schema.Name, columns))
+		for _, c := range columns {
+			for _, classification := range c.Classifications {
+				writeCsvRecord(writer, []string{
+					c.SchemaName,
+					c.EntityName,
+					c.Name,
+					c.DataType,
+					classification.Name,
+					classification.Category,
+					strconv.FormatFloat(classification.Confidence, 'f', 2, 64),
+					strings.Join(classification.Evidence, "; "),
+					c.MaskingStrategy})
+			}
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Fatal("Error writing CSV:", err)
+	}
+	writeFile(buffer.Bytes(), outputFileName)
+	fmt.Println("Sensitivity report created successfully.")
+}
+
+// Returns the columns with classifications of the entities and their collapsed partitions, sorted by entity
+func getSensitiveColumns(entities []model.Entity) []model.Column {
+	columns := make([]model.Column, 0)
+	for _, entity := range sortEntities(entities) {
+		for _, c := range entity.Columns {
+			if len(c.Classifications) > 0 {
+				columns = append(columns, c)
+			}
+		}
+		columns = append(columns, getSensitiveColumns(entity.Partitions)...)
+	}
+	return columns
+}
+
+// Builds the row with the totals of a schema. Its column with the evidence describes the totals
+func getSchemaSensitivitySummary(schemaName string, columns []model.Column) []string {
+	piiCount, phiCount, maskedCount := 0, 0, 0
+	for _, c := range columns {
+		hasPii, hasPhi := false, false
+		for _, classification := range c.Classifications {
+			hasPii = hasPii || strings.EqualFold(classification.Category, "PII")
+			hasPhi = hasPhi || strings.EqualFold(classification.Category, "PHI")
+		}
+		if hasPii {
+			piiCount++
+		}
+		if hasPhi {
+			phiCount++
+		}
+		if c.MaskingStrategy != "" {
+			maskedCount++
+		}
+	}
+	summary := fmt.Sprintf("%d sensitive columns: %d PII, %d PHI, %d masked",
+		len(columns), piiCount, phiCount, maskedCount)
+	return []string{schemaName, "", "", "", "", "", "", summary, ""}
+}
@@ -2,9 +2,11 @@
 package service
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"os"
 
 	"github.com/PDCMFinder/db-descriptor/internal/extractor"
 	"github.com/PDCMFinder/db-descriptor/pkg/connector"
@@ -17,11 +19,41 @@ func GetDbDescription(input connector.Input) model.DatabaseDescription {
 	if err != nil {
 		log.Fatal(err)
 	}
-	dbDescriptionExtractor := extractor.New(dbConnector)
+	dbDescriptionExtractor := extractor.New(dbConnector, input)
 	databaseDescription := dbDescriptionExtractor.ExtractDescription()
 	return databaseDescription
 }
 
+// Returns the description of the results of the queries in the input, as entities of type `model.Query`, without
+// executing them. The description of the database is extracted too, to find the entities the queries read.
+func DescribeQueries(input connector.Input) []model.Entity {
+	return GetDbDescription(input).Queries
+}
+
+// Checks the SQL statements of the query files in the input against the database, preparing them without executing
+// them. Returns the problems found, with the file and line of each one.
+func CheckQueries(input connector.Input) []model.QueryIssue {
+	dbConnector, err := getDBConnector(input)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return extractor.New(dbConnector, input).CheckQueries()
+}
+
+// Checks the SQL statements of the query files against a description of the database saved as a JSON file, without
+// connecting to the database. Returns the problems found, with the file and line of each one.
+func CheckQueriesAgainstDescription(descriptionFileName string, queryFiles []string) []model.QueryIssue {
+	content, err := os.ReadFile(descriptionFileName)
+	if err != nil {
+		log.Fatal("Could not read the description file. Error: ", err)
+	}
+	var databaseDescription model.DatabaseDescription
+	if err := json.Unmarshal(content, &databaseDescription); err != nil {
+		log.Fatal("Could not parse the description file. Error: ", err)
+	}
+	return extractor.CheckQueriesAgainstDescription(databaseDescription, queryFiles)
+}
+
 // Helper function to get the appropiate DBConnector implementation. Really simple logic as only one DBConnector
 // is implemented. This could be much more sophisticated, following a plugin-like approach.
 func getDBConnector(input connector.Input) (connector.DBConnector, error) {